@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"delta-sync/internal/db"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Compares the old one-query-per-hash lookup with the batched GetMissingChunks against the
+// database in DATABASE_URL_DELTASYNC. Only random hashes are looked up, nothing is written.
+// `go test -bench GetMissingChunks ./internal/db` runs the same comparison as a benchmark.
+func main() {
+	// 1. Capture the benchmark size via command line flags
+	count := flag.Int("n", 160000, "Hashes in the simulated signature (~10 GB at 64 KB average chunks)")
+	sample := flag.Int("sample", 2000, "Hashes timed with the sequential lookup before extrapolating")
+	flag.Parse()
+
+	remoteDB := db.InitPostgres()
+
+	hashes := make([]string, *count)
+	for i := range hashes {
+		hashes[i] = randomHash()
+	}
+	if *sample > *count {
+		*sample = *count
+	}
+
+	// 2. Baseline: the previous implementation, one SELECT EXISTS round trip per hash
+	start := time.Now()
+	for _, hash := range hashes[:*sample] {
+		var exists bool
+		query := `SELECT EXISTS(SELECT 1 FROM chunks WHERE hash=$1);`
+		if err := remoteDB.Pool.QueryRow(context.Background(), query, hash).Scan(&exists); err != nil {
+			log.Fatalf("❌ Sequential lookup failed: %v", err)
+		}
+	}
+	perHash := time.Since(start) / time.Duration(*sample)
+	sequential := perHash * time.Duration(*count)
+	fmt.Printf("🐢 Sequential: %s per hash, ~%s for %d hashes (extrapolated from %d)\n",
+		perHash, sequential.Round(time.Millisecond), *count, *sample)
+
+	// 3. Set-based lookup over the full signature
+	start = time.Now()
+	missing, err := remoteDB.GetMissingChunks("", hashes)
+	if err != nil {
+		log.Fatalf("❌ Batched lookup failed: %v", err)
+	}
+	batched := time.Since(start)
+	fmt.Printf("🚀 Batched:    %s for %d hashes (%d missing, batches of %d)\n",
+		batched.Round(time.Millisecond), *count, len(missing), db.MissingChunksBatchSize)
+
+	fmt.Printf("📈 Speed-up:   %.1fx\n", float64(sequential)/float64(batched))
+}
+
+func randomHash() string {
+	buf := make([]byte, 32)
+	rand.Read(buf)
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"context"
+	"delta-sync/pkg/deltasync"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// runPush uploads one file. It is named by its path relative to the current directory, so a
+// later `watch .` or `status` from the same place agrees on the name.
+func runPush(ctx context.Context, args []string) error {
+	fs, g := newFlagSet("push")
+	name := fs.String("name", "", "Name to store the file under (default: its path relative to the current directory)")
+	args = parseArgs(fs, args)
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	path, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory; use watch to sync a tree", args[0])
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if *name == "" {
+		*name = defaultName(cwd, path)
+	}
+
+	c, err := g.connect(cwd)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	result, err := c.PushFile(ctx, path, *name)
+	if err != nil {
+		return err
+	}
+	return g.emit(struct {
+		Name          string `json:"name"`
+		Version       int    `json:"version,omitempty"`
+		Unchanged     bool   `json:"unchanged"`
+		Chunks        int    `json:"chunks"`
+		Uploaded      int    `json:"uploaded"`
+		UploadedBytes int64  `json:"uploaded_bytes"`
+	}{result.Name, result.Version, result.Unchanged, result.Chunks, result.Uploaded, result.UploadedBytes}, func() {
+		if !result.Unchanged {
+			fmt.Printf("📦 %s: %d of %d chunks uploaded (%d bytes)\n", result.Name, result.Uploaded, result.Chunks, result.UploadedBytes)
+		}
+	})
+}
+
+// defaultName is the name a pushed file gets: its slash-separated path below dir, or its base
+// name when it lies outside
+func defaultName(dir, path string) string {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		return filepath.Base(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// runPull downloads one file, by default to where its name points below the current directory
+func runPull(ctx context.Context, args []string) error {
+	fs, g := newFlagSet("pull")
+	dest := fs.String("o", "", "Where to write the file (default: its name, relative to the current directory)")
+	version := fs.Int("version", 0, "Version to restore from the file's history (default: the current one)")
+	args = parseArgs(fs, args)
+	if len(args) != 1 {
+		return errUsage
+	}
+	name := args[0]
+
+	if *dest == "" {
+		rel := filepath.FromSlash(name)
+		if !filepath.IsLocal(rel) {
+			return fmt.Errorf("%q does not name a path below the current directory; pass -o", name)
+		}
+		*dest = rel
+	}
+	path, err := filepath.Abs(*dest)
+	if err != nil {
+		return err
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	c, err := g.connect(cwd)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.PullVersion(ctx, name, *version, path); err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return g.emit(struct {
+		Name    string `json:"name"`
+		Version int    `json:"version,omitempty"`
+		Path    string `json:"path"`
+		Size    int64  `json:"size"`
+	}{name, *version, path, info.Size()}, func() {
+		fmt.Printf("🎉 %s reconstructed at %s (%d bytes)\n", name, *dest, info.Size())
+	})
+}
+
+// runList lists the files on the server
+func runList(ctx context.Context, args []string) error {
+	fs, g := newFlagSet("ls")
+	if len(parseArgs(fs, args)) != 0 {
+		return errUsage
+	}
+
+	c, err := g.connect("")
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	files, err := c.ListFiles(ctx)
+	if err != nil {
+		return err
+	}
+	type entry struct {
+		Name      string    `json:"name"`
+		Chunks    int       `json:"chunks"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+	out := make([]entry, 0, len(files))
+	for _, f := range files {
+		out = append(out, entry{f.Name, len(f.Hashes), f.UpdatedAt})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return g.emit(out, func() {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tCHUNKS\tUPDATED")
+		for _, f := range out {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", f.Name, f.Chunks, f.UpdatedAt.Local().Format(time.DateTime))
+		}
+		w.Flush()
+	})
+}
+
+// runHistory lists the stored versions of a file, newest first
+func runHistory(ctx context.Context, args []string) error {
+	fs, g := newFlagSet("history")
+	args = parseArgs(fs, args)
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	c, err := g.connect("")
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	versions, err := c.History(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("%w: %s", deltasync.ErrNotFound, args[0])
+	}
+	type entry struct {
+		Version   int       `json:"version"`
+		Size      int64     `json:"size"`
+		Chunks    int       `json:"chunks"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+	out := make([]entry, 0, len(versions))
+	for _, v := range versions {
+		out = append(out, entry{v.Number, v.Size, v.Chunks, v.CreatedAt})
+	}
+	return g.emit(out, func() {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tSIZE\tCHUNKS\tCREATED")
+		for _, v := range out {
+			fmt.Fprintf(w, "%d\t%d\t%d\t%s\n", v.Version, v.Size, v.Chunks, v.CreatedAt.Local().Format(time.DateTime))
+		}
+		w.Flush()
+	})
+}
+
+// runWatch keeps a file or tree in sync until interrupted. With --json every progress message
+// becomes one JSON object per line.
+func runWatch(ctx context.Context, args []string) error {
+	fs, g := newFlagSet("watch")
+	sched, pull := watchFlags(fs)
+	args = parseArgs(fs, args)
+	if len(args) != 1 {
+		return errUsage
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	opts := []deltasync.Option{
+		deltasync.WithDebounce(sched.Debounce),
+		deltasync.WithWorkers(sched.Workers),
+		deltasync.WithPullInterval(*pull),
+	}
+	if g.json {
+		enc := json.NewEncoder(os.Stdout)
+		opts = append(opts, deltasync.WithLogger(func(format string, args ...any) {
+			enc.Encode(struct {
+				Time    time.Time `json:"time"`
+				Message string    `json:"message"`
+			}{time.Now(), strings.TrimSpace(fmt.Sprintf(format, args...))})
+		}))
+	}
+	c, err := g.connect(cwd, opts...)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	g.printf("🔗 Connecting to server: %s\n", g.server)
+	if err := c.Watch(ctx, args[0]); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// statusRoot parses the optional directory argument of status and verify
+func statusRoot(args []string) (string, error) {
+	switch len(args) {
+	case 0:
+		return ".", nil
+	case 1:
+		if info, err := os.Stat(args[0]); err != nil {
+			return "", err
+		} else if !info.IsDir() {
+			return "", fmt.Errorf("%s is not a directory", args[0])
+		}
+		return args[0], nil
+	}
+	return "", errUsage
+}
+
+// runStatus shows how a tree differs from the server, naming files as a `watch` of the tree does
+func runStatus(ctx context.Context, args []string) error {
+	fs, g := newFlagSet("status")
+	root, err := statusRoot(parseArgs(fs, args))
+	if err != nil {
+		return err
+	}
+
+	c, err := g.connect(root)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	statuses, err := c.Status(ctx)
+	if err != nil {
+		return err
+	}
+	type entry struct {
+		Name  string `json:"name"`
+		Path  string `json:"path"`
+		State string `json:"state"`
+	}
+	out := make([]entry, 0, len(statuses))
+	for _, st := range statuses {
+		out = append(out, entry{st.Name, st.Path, string(st.State)})
+	}
+	return g.emit(out, func() {
+		inSync := 0
+		for _, st := range out {
+			if st.State == string(deltasync.InSync) {
+				inSync++
+				continue
+			}
+			fmt.Printf("%-10s %s\n", st.State, st.Name)
+		}
+		fmt.Printf("✨ %d of %d files in sync\n", inSync, len(out))
+	})
+}
+
+// runVerify checks every file that exists both locally and on the server byte for byte and
+// fails if any differs
+func runVerify(ctx context.Context, args []string) error {
+	fs, g := newFlagSet("verify")
+	root, err := statusRoot(parseArgs(fs, args))
+	if err != nil {
+		return err
+	}
+
+	c, err := g.connect(root)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	statuses, err := c.Status(ctx)
+	if err != nil {
+		return err
+	}
+	type entry struct {
+		Name  string `json:"name"`
+		Path  string `json:"path"`
+		OK    bool   `json:"ok"`
+		Error string `json:"error,omitempty"`
+	}
+	var out []entry
+	failed := 0
+	for _, st := range statuses {
+		switch st.State {
+		case deltasync.Added, deltasync.Deleted, deltasync.Remote:
+			continue // only one side to compare
+		}
+		e := entry{Name: st.Name, Path: st.Path, OK: true}
+		if err := c.VerifyFile(ctx, st.Path, st.Name); err != nil {
+			var mismatch *deltasync.MismatchError
+			if !errors.As(err, &mismatch) {
+				return err
+			}
+			e.OK, e.Error = false, fmt.Sprintf("%s at byte %d", mismatch.Reason, mismatch.Offset)
+			failed++
+		}
+		out = append(out, e)
+	}
+
+	err = g.emit(out, func() {
+		for _, e := range out {
+			if e.OK {
+				fmt.Printf("✅ %s\n", e.Name)
+			} else {
+				fmt.Printf("❌ %s: %s\n", e.Name, e.Error)
+			}
+		}
+		fmt.Printf("%d of %d files match the server\n", len(out)-failed, len(out))
+	})
+	if err == nil && failed > 0 {
+		return errFailed
+	}
+	return err
+}
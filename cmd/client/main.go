@@ -2,172 +2,221 @@ package main
 
 import (
 	"context"
-	"delta-sync/delta-sync-pb/pkg/pb"
-	"delta-sync/internal/chunker"
 	"delta-sync/internal/db"
+	"delta-sync/internal/encrypt"
+	"delta-sync/internal/scheduler"
+	"delta-sync/pkg/deltasync"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"io"
-	"log"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
+)
+
+// command is one subcommand of the client
+type command struct {
+	name    string
+	args    string // positional arguments as shown in usage
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
 
-	"github.com/fsnotify/fsnotify"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
+var commands = []command{
+	{"push", "<path>", "Upload a file, sending only the chunks the server lacks", runPush},
+	{"pull", "<name> [-o dest]", "Download a file, fetching only the chunks not cached locally", runPull},
+	{"ls", "", "List the files on the server", runList},
+	{"history", "<name>", "List every stored version of a file", runHistory},
+	{"watch", "<path>", "Keep a file or directory tree in sync until interrupted", runWatch},
+	{"status", "[dir]", "Compare a directory tree with the server", runStatus},
+	{"verify", "[dir]", "Check that local files hold exactly the server's current versions", runVerify},
+}
+
+// errUsage makes main print the command's usage; errFailed exits non-zero after the command
+// has already reported why
+var (
+	errUsage  = errors.New("usage")
+	errFailed = errors.New("failed")
 )
 
 func main() {
-	// 1. Capture the file path and server address via command line flags
-	filePath := flag.String("file", "", "The full path of the file you want to sync")
-	// Updated default to your Render URL
-	serverAddr := flag.String("server", "delta-sync-production.up.railway.app:443", "Server address")
-	flag.Parse()
-
-	if *filePath == "" {
-		fmt.Println("❌ Usage error: You must specify a file to watch.")
-		fmt.Println("Usage: go run cmd/client/main.go -file=\"your_file_path_here\"")
-		os.Exit(1)
+	args := os.Args[1:]
+	if len(args) > 0 && strings.HasPrefix(args[0], "-") && !isHelp(args[0]) {
+		// The client used to be a single watcher driven by -file or -dir
+		args = legacyArgs(args)
 	}
-
-	if _, err := os.Stat(*filePath); os.IsNotExist(err) {
-		log.Fatalf("❌ Error: The file %s does not exist.", *filePath)
+	if len(args) == 0 || isHelp(args[0]) {
+		usage()
+		os.Exit(2)
 	}
 
-	// 2. Setup fsnotify Watcher
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer watcher.Close()
-
-	// 3. Background monitoring loop
-	go func() {
-		for {
-			select {
-			case event, ok := <-watcher.Events:
-				if !ok {
-					return
-				}
-				if event.Op&fsnotify.Write == fsnotify.Write {
-					fmt.Printf("📝 Changes detected in: %s. Initiating Delta-Sync...\n", event.Name)
-					performSync(*filePath, *serverAddr)
-				}
-			case err, ok := <-watcher.Errors:
-				if !ok {
-					return
-				}
-				log.Println("watcher error:", err)
-			}
-		}
-	}()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	err = watcher.Add(*filePath)
-	if err != nil {
-		log.Fatal(err)
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		err := cmd.run(ctx, args[1:])
+		switch {
+		case err == nil:
+		case errors.Is(err, errUsage):
+			fmt.Fprintf(os.Stderr, "Usage: client %s [flags] %s\n", cmd.name, cmd.args)
+			os.Exit(2)
+		case errors.Is(err, errFailed):
+			os.Exit(1)
+		default:
+			fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
-
-	fmt.Printf("👁️  Delta-Sync Watcher active on: %s\n", *filePath)
-	fmt.Printf("🔗 Connecting to server: %s\n", *serverAddr)
-	
-	performSync(*filePath, *serverAddr)
-
-	select {}
+	fmt.Fprintf(os.Stderr, "❌ Unknown command %q\n\n", args[0])
+	usage()
+	os.Exit(2)
 }
 
-func performSync(filePath string, addr string) {
-	localDB := db.InitSQLite("client_metadata.db")
-	defer localDB.Conn.Close()
+func isHelp(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help" || arg == "help"
+}
 
-	chunks, err := chunker.AnalyzeFileVSC(filePath)
-	if err != nil {
-		log.Printf("Analysis failed: %v", err)
-		return
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: client <command> [flags] [arguments]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %-18s %s\n", cmd.name, cmd.args, cmd.summary)
 	}
+	fmt.Fprintln(os.Stderr, "\nEvery command accepts -server, -token, -key-file, -cache-mb and --json;")
+	fmt.Fprintln(os.Stderr, "run client <command> -h for the rest.")
+}
 
-	var hashList []string
-	for _, c := range chunks {
-		hashList = append(hashList, c.Hash)
+// legacyArgs turns the old `-file path` / `-dir path` invocation into `watch path`, keeping the
+// other flags
+func legacyArgs(args []string) []string {
+	var target string
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		if name != "file" && name != "dir" {
+			rest = append(rest, args[i])
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		target = value
+	}
+	if target == "" {
+		fmt.Fprintln(os.Stderr, "❌ Usage error: You must specify a command, or -file or -dir to watch.")
+		return nil
 	}
+	return append(append([]string{"watch"}, rest...), target)
+}
 
-	localDB.SaveFileIndex(filePath, strings.Join(hashList, ","))
+// globals are the flags every command shares
+type globals struct {
+	server  string
+	token   string
+	keyFile string
+	cacheMB int64
+	json    bool
+}
 
-	// 4. Establish SECURE gRPC Transport connection
-	// Using system certs to allow connection to Render's HTTPS/TLS endpoint
+// newFlagSet creates a command's flag set with the shared flags already registered
+func newFlagSet(name string) (*flag.FlagSet, *globals) {
+	g := &globals{}
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	// Updated default to your Render URL
+	fs.StringVar(&g.server, "server", "delta-sync-production.up.railway.app:443", "Server address")
+	fs.StringVar(&g.token, "token", os.Getenv("DELTASYNC_TOKEN"), "API key or JWT identifying you to the server (default $DELTASYNC_TOKEN)")
+	fs.StringVar(&g.keyFile, "key-file", "", "File holding a hex-encoded 32-byte key (openssl rand -hex 32) for end-to-end encryption; DELTASYNC_KEY works too")
+	fs.Int64Var(&g.cacheMB, "cache-mb", db.DefaultCacheLimit>>20, "Size limit of the local chunk cache in MiB; least recently used chunks are evicted beyond it (0 = unlimited)")
+	fs.BoolVar(&g.json, "json", false, "Print machine-readable JSON instead of progress messages")
+	return fs, g
+}
 
-	creds := credentials.NewClientTLSFromCert(nil, "") 
-	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(creds))
-	if err != nil {
-		log.Printf("Connection failed: %v", err)
-		return
+// parseArgs parses flags wherever they appear, so `push notes.txt --json` works like
+// `push --json notes.txt`, and returns the positional arguments
+func parseArgs(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
 	}
-	defer conn.Close()
-	client := pb.NewDeltaSyncClient(conn)
+}
 
-	// Increased timeout to 30s to account for potential Render "Cold Start"
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+// connect creates a client naming files relative to root. Progress messages are printed unless
+// JSON output was asked for.
+func (g *globals) connect(root string, extra ...deltasync.Option) (*deltasync.Client, error) {
+	cacheLimit := g.cacheMB << 20
+	if cacheLimit == 0 {
+		cacheLimit = -1
+	}
+	opts := []deltasync.Option{
+		deltasync.WithAddress(g.server),
+		deltasync.WithToken(g.token),
+		deltasync.WithRoot(root),
+		deltasync.WithCacheLimit(cacheLimit),
+	}
+	if !g.json {
+		opts = append(opts, deltasync.WithLogger(func(format string, args ...any) { fmt.Printf(format, args...) }))
+	}
 
-	resp, err := client.GetMissingChunks(ctx, &pb.FileSignature{
-		FileId:      filePath,
-		ChunkHashes: hashList,
-	})
+	// Optional end-to-end encryption; the key never leaves this machine
+	key, err := loadEncryptionKey(g.keyFile)
 	if err != nil {
-		log.Printf("Sync failed: %v", err)
-		return
+		return nil, err
 	}
+	if key != nil {
+		opts = append(opts, deltasync.WithEncryptionKey(key))
+		g.printf("🔐 End-to-end encryption enabled\n")
+	}
+	return deltasync.New(append(opts, extra...)...)
+}
 
-	if len(resp.MissingHashes) > 0 {
-		fmt.Printf("📤 Syncing %d new/modified chunks...\n", len(resp.MissingHashes))
-		stream, err := client.UploadChunks(context.Background())
-		if err != nil {
-			log.Printf("Stream failed: %v", err)
-			return
-		}
+// printf prints a progress message unless JSON output was asked for
+func (g *globals) printf(format string, args ...any) {
+	if !g.json {
+		fmt.Printf(format, args...)
+	}
+}
 
-		for _, missingHash := range resp.MissingHashes {
-			for _, c := range chunks {
-				if c.Hash == missingHash {
-					stream.Send(&pb.ChunkPayload{Hash: c.Hash, Data: c.Data})
-				}
-			}
-		}
-		stream.CloseAndRecv()
-		fmt.Println("✅ Delta-Sync Complete!")
-	} else {
-		fmt.Println("✨ Server is already synchronized with this version.")
+// emit prints v as indented JSON in JSON mode and calls human otherwise
+func (g *globals) emit(v any, human func()) error {
+	if !g.json {
+		human()
+		return nil
 	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
 }
 
-func downloadFromServer(targetFileName string, savePath string, addr string) {
-	// Using secure credentials here as well
-	creds := credentials.NewClientTLSFromCert(nil, "")
-	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(creds))
-	if err != nil {
-		log.Printf("Connection failed: %v", err)
-		return
+// loadEncryptionKey reads the key from -key-file or DELTASYNC_KEY; nil means encryption is off
+func loadEncryptionKey(keyFile string) ([]byte, error) {
+	if keyFile != "" {
+		return encrypt.LoadKey(keyFile)
 	}
-	defer conn.Close()
-	client := pb.NewDeltaSyncClient(conn)
-	
-	stream, err := client.DownloadFile(context.Background(), &pb.FileRequest{FileName: targetFileName})
-	if err != nil {
-		log.Fatal(err)
+	if env := os.Getenv("DELTASYNC_KEY"); env != "" {
+		return encrypt.ParseKey(env)
 	}
+	return nil, nil
+}
 
-	file, _ := os.Create(savePath)
-	defer file.Close()
-
-	for {
-		chunk, err := stream.Recv()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			log.Fatal(err)
-		}
-		file.Write(chunk.Data)
-	}
-	fmt.Println("🎉 File reconstructed locally!")
-}
\ No newline at end of file
+// watchFlags registers the flags only watch uses
+func watchFlags(fs *flag.FlagSet) (*scheduler.Options, *time.Duration) {
+	var opts scheduler.Options
+	fs.DurationVar(&opts.Debounce, "debounce", scheduler.DefaultDebounce, "How long a file must stay unchanged before it is synced")
+	fs.IntVar(&opts.Workers, "workers", scheduler.DefaultWorkers, "How many files may sync at the same time")
+	pull := fs.Duration("pull", 0, "When watching a directory, poll the server this often and pull newer files down (0 disables)")
+	return &opts, pull
+}
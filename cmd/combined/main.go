@@ -2,7 +2,11 @@ package main
 
 import (
 	"delta-sync/delta-sync-pb/pkg/pb"
+	"delta-sync/internal/auth"
+	"delta-sync/internal/dashboard"
 	"delta-sync/internal/db"
+	"delta-sync/internal/events"
+	"delta-sync/internal/service"
 	"fmt"
 	"log"
 	"net/http"
@@ -11,26 +15,49 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
-type server struct {
-    pb.UnimplementedDeltaSyncServer
-    remoteDB *db.RemoteDB
-}
-
 func main() {
 	remoteDB := db.InitPostgres() // Reads DATABASE_URL_DELTASYNC
 	port := os.Getenv("PORT")
 	if port == "" { port = "8080" }
 
-	// 1. Initialize gRPC Server
-	grpcServer := grpc.NewServer()
-	pb.RegisterDeltaSyncServer(grpcServer, &server{remoteDB: remoteDB})
+	authenticator, err := auth.FromEnv()
+	if err != nil {
+		log.Fatalf("invalid authentication configuration: %v", err)
+	}
+
+	// Sync events go straight from the gRPC service to the dashboard, no loopback HTTP involved
+	bus := events.NewBus()
+
+	// 1. Initialize gRPC Server (same service as cmd/server)
+	svc, err := service.FromEnv(remoteDB, bus)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := svc.StartGarbageCollector(); err != nil {
+		log.Fatal(err)
+	}
+	grpcServer := svc.GRPCServer(authenticator)
+
+	// Downloads stream through the gRPC service on our own port, like they do in cmd/web
+	conn, err := grpc.Dial("localhost:"+port, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalf("failed to set up internal gRPC client: %v", err)
+	}
+	defer conn.Close()
 
-	// 2. Initialize Echo Dashboard
+	// 2. Initialize Echo Dashboard (same routes as cmd/web)
 	e := echo.New()
-	e.GET("/", func(c echo.Context) error { return c.File("web/index.html") })
-	// ... (add your other dashboard routes here) ...
+	dashboard.Register(e, dashboard.Config{
+		RemoteDB: remoteDB,
+		Client:   pb.NewDeltaSyncClient(conn),
+		Events:   dashboard.BusSource(bus),
+		Auth:     authenticator,
+
+		AllowedOrigins: dashboard.OriginsFromEnv(),
+	})
 
 	// 3. Create a unified handler
 	// This function checks if the request is gRPC; if not, it sends it to Echo
@@ -42,6 +69,12 @@ func main() {
 		}
 	})
 
+	// gRPC needs HTTP/2; behind a TLS-terminating proxy it arrives as plaintext HTTP/2 (h2c)
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	srv := &http.Server{Addr: ":" + port, Handler: mixedHandler, Protocols: protocols}
+
 	fmt.Printf("🚀 Unified Server starting on port %s\n", port)
-	log.Fatal(http.ListenAndServe(":"+port, mixedHandler))
-}
\ No newline at end of file
+	log.Fatal(srv.ListenAndServe())
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"delta-sync/internal/db"
+	"delta-sync/internal/gc"
+	"delta-sync/internal/store"
+	"flag"
+	"fmt"
+	"log"
+)
+
+func main() {
+	// 1. Capture the collection settings via command line flags
+	dryRun := flag.Bool("dry-run", false, "Only report how many chunks and bytes could be reclaimed")
+	batchSize := flag.Int("batch", gc.DefaultOptions.BatchSize, "Chunks examined and deleted per round trip")
+	grace := flag.Duration("grace", gc.DefaultOptions.GracePeriod, "Never collect chunks younger than this")
+	keepVersions := flag.Int("keep-versions", 0, "History entries to keep per file (0 keeps all)")
+	flag.Parse()
+
+	// 2. Initialize PostgreSQL connection (reads from DATABASE_URL_DELTASYNC)
+	remoteDB := db.InitPostgres()
+	chunkStore, err := store.FromEnv(remoteDB.Pool)
+	if err != nil {
+		log.Fatalf("❌ Invalid chunk store configuration: %v", err)
+	}
+
+	// 3. Run a single pass and print the report
+	report, err := gc.Run(remoteDB, chunkStore, gc.Options{
+		DryRun:       *dryRun,
+		BatchSize:    *batchSize,
+		GracePeriod:  *grace,
+		KeepVersions: *keepVersions,
+	})
+	if err != nil {
+		log.Fatalf("❌ Garbage collection failed: %v", err)
+	}
+	fmt.Printf("🧹 %s\n", report)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"delta-sync/internal/db"
+	"delta-sync/internal/store"
+	"flag"
+	"fmt"
+	"log"
+)
+
+// migrate-chunks moves chunk bytes from the chunks table into the store CHUNK_STORE names, for
+// servers switching an existing database from the default Postgres store to fs or s3.
+// Until it has run, the server reads those chunks from Postgres.
+func main() {
+	// 1. Capture the migration settings via command line flags
+	batchSize := flag.Int("batch", 100, "Chunks read from Postgres per round trip")
+	flag.Parse()
+
+	// 2. Initialize PostgreSQL connection (reads from DATABASE_URL_DELTASYNC) and the target store
+	remoteDB := db.InitPostgres()
+	chunkStore, err := store.FromEnv(remoteDB.Pool)
+	if err != nil {
+		log.Fatalf("❌ Invalid chunk store configuration: %v", err)
+	}
+
+	// 3. Move every chunk still held in Postgres
+	report, err := store.Migrate(context.Background(), chunkStore, *batchSize)
+	if report != nil {
+		fmt.Printf("🚚 Moved %d chunks (%d bytes) out of Postgres\n", report.Chunks, report.Bytes)
+	}
+	if err != nil {
+		log.Fatalf("❌ Migration failed: %v", err)
+	}
+}
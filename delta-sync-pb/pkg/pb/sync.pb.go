@@ -9,6 +9,7 @@ package pb
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -21,9 +22,60 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// compression applied to ChunkPayload.data; the hash always covers the uncompressed bytes
+type Codec int32
+
+const (
+	Codec_CODEC_STORED Codec = 0
+	Codec_CODEC_GZIP   Codec = 1
+	Codec_CODEC_ZSTD   Codec = 2
+)
+
+// Enum value maps for Codec.
+var (
+	Codec_name = map[int32]string{
+		0: "CODEC_STORED",
+		1: "CODEC_GZIP",
+		2: "CODEC_ZSTD",
+	}
+	Codec_value = map[string]int32{
+		"CODEC_STORED": 0,
+		"CODEC_GZIP":   1,
+		"CODEC_ZSTD":   2,
+	}
+)
+
+func (x Codec) Enum() *Codec {
+	p := new(Codec)
+	*p = x
+	return p
+}
+
+func (x Codec) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Codec) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_sync_proto_enumTypes[0].Descriptor()
+}
+
+func (Codec) Type() protoreflect.EnumType {
+	return &file_api_proto_sync_proto_enumTypes[0]
+}
+
+func (x Codec) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Codec.Descriptor instead.
+func (Codec) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_sync_proto_rawDescGZIP(), []int{0}
+}
+
 type FileRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	FileName      string                 `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	Version       int32                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"` // 0 means the current version
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -65,12 +117,21 @@ func (x *FileRequest) GetFileName() string {
 	return ""
 }
 
+func (x *FileRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
 type FileSignature struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
-	ChunkHashes   []string               `protobuf:"bytes,2,rep,name=chunk_hashes,json=chunkHashes,proto3" json:"chunk_hashes,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	FileId         string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	ChunkHashes    []string               `protobuf:"bytes,2,rep,name=chunk_hashes,json=chunkHashes,proto3" json:"chunk_hashes,omitempty"`
+	FileSize       int64                  `protobuf:"varint,3,opt,name=file_size,json=fileSize,proto3" json:"file_size,omitempty"`
+	AcceptedCodecs []Codec                `protobuf:"varint,4,rep,packed,name=accepted_codecs,json=acceptedCodecs,proto3,enum=sync.Codec" json:"accepted_codecs,omitempty"` // codecs the client can send, in order of preference
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *FileSignature) Reset() {
@@ -117,9 +178,25 @@ func (x *FileSignature) GetChunkHashes() []string {
 	return nil
 }
 
+func (x *FileSignature) GetFileSize() int64 {
+	if x != nil {
+		return x.FileSize
+	}
+	return 0
+}
+
+func (x *FileSignature) GetAcceptedCodecs() []Codec {
+	if x != nil {
+		return x.AcceptedCodecs
+	}
+	return nil
+}
+
 type MissingChunksResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	MissingHashes []string               `protobuf:"bytes,1,rep,name=missing_hashes,json=missingHashes,proto3" json:"missing_hashes,omitempty"`
+	Codec         Codec                  `protobuf:"varint,2,opt,name=codec,proto3,enum=sync.Codec" json:"codec,omitempty"`         // codec the server picked for this upload
+	SessionId     string                 `protobuf:"bytes,3,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"` // identifies the upload so an interrupted stream can resume; empty when nothing is missing
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -161,11 +238,26 @@ func (x *MissingChunksResponse) GetMissingHashes() []string {
 	return nil
 }
 
+func (x *MissingChunksResponse) GetCodec() Codec {
+	if x != nil {
+		return x.Codec
+	}
+	return Codec_CODEC_STORED
+}
+
+func (x *MissingChunksResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
 type ChunkPayload struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Hash          string                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
 	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
-	Size          int32                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Size          int32                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"` // uncompressed size
+	Codec         Codec                  `protobuf:"varint,4,opt,name=codec,proto3,enum=sync.Codec" json:"codec,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -221,17 +313,177 @@ func (x *ChunkPayload) GetSize() int32 {
 	return 0
 }
 
+func (x *ChunkPayload) GetCodec() Codec {
+	if x != nil {
+		return x.Codec
+	}
+	return Codec_CODEC_STORED
+}
+
+// first message of an upload: what follows, so the server can report real progress
+type UploadHeader struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	SessionId     string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"` // upload session the chunks belong to, if any
+	ChunkCount    int32                  `protobuf:"varint,3,opt,name=chunk_count,json=chunkCount,proto3" json:"chunk_count,omitempty"`
+	TotalBytes    int64                  `protobuf:"varint,4,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"` // sum of the chunks' uncompressed sizes
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadHeader) Reset() {
+	*x = UploadHeader{}
+	mi := &file_api_proto_sync_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadHeader) ProtoMessage() {}
+
+func (x *UploadHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sync_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadHeader.ProtoReflect.Descriptor instead.
+func (*UploadHeader) Descriptor() ([]byte, []int) {
+	return file_api_proto_sync_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UploadHeader) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *UploadHeader) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *UploadHeader) GetChunkCount() int32 {
+	if x != nil {
+		return x.ChunkCount
+	}
+	return 0
+}
+
+func (x *UploadHeader) GetTotalBytes() int64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+type UploadRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*UploadRequest_Header
+	//	*UploadRequest_Chunk
+	Payload       isUploadRequest_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadRequest) Reset() {
+	*x = UploadRequest{}
+	mi := &file_api_proto_sync_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadRequest) ProtoMessage() {}
+
+func (x *UploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sync_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadRequest.ProtoReflect.Descriptor instead.
+func (*UploadRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_sync_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UploadRequest) GetPayload() isUploadRequest_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *UploadRequest) GetHeader() *UploadHeader {
+	if x != nil {
+		if x, ok := x.Payload.(*UploadRequest_Header); ok {
+			return x.Header
+		}
+	}
+	return nil
+}
+
+func (x *UploadRequest) GetChunk() *ChunkPayload {
+	if x != nil {
+		if x, ok := x.Payload.(*UploadRequest_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+type isUploadRequest_Payload interface {
+	isUploadRequest_Payload()
+}
+
+type UploadRequest_Header struct {
+	Header *UploadHeader `protobuf:"bytes,1,opt,name=header,proto3,oneof"`
+}
+
+type UploadRequest_Chunk struct {
+	Chunk *ChunkPayload `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*UploadRequest_Header) isUploadRequest_Payload() {}
+
+func (*UploadRequest_Chunk) isUploadRequest_Payload() {}
+
 type UploadStatus struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	FailedHash    string                 `protobuf:"bytes,3,opt,name=failed_hash,json=failedHash,proto3" json:"failed_hash,omitempty"` // chunk whose data did not match its hash, if any
+	ChunksStored  int32                  `protobuf:"varint,4,opt,name=chunks_stored,json=chunksStored,proto3" json:"chunks_stored,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *UploadStatus) Reset() {
 	*x = UploadStatus{}
-	mi := &file_api_proto_sync_proto_msgTypes[4]
+	mi := &file_api_proto_sync_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -243,7 +495,7 @@ func (x *UploadStatus) String() string {
 func (*UploadStatus) ProtoMessage() {}
 
 func (x *UploadStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_sync_proto_msgTypes[4]
+	mi := &file_api_proto_sync_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -256,7 +508,7 @@ func (x *UploadStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UploadStatus.ProtoReflect.Descriptor instead.
 func (*UploadStatus) Descriptor() ([]byte, []int) {
-	return file_api_proto_sync_proto_rawDescGZIP(), []int{4}
+	return file_api_proto_sync_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *UploadStatus) GetSuccess() bool {
@@ -273,81 +525,1378 @@ func (x *UploadStatus) GetMessage() string {
 	return ""
 }
 
-var File_api_proto_sync_proto protoreflect.FileDescriptor
+func (x *UploadStatus) GetFailedHash() string {
+	if x != nil {
+		return x.FailedHash
+	}
+	return ""
+}
 
-const file_api_proto_sync_proto_rawDesc = "" +
-	"\n" +
-	"\x14api/proto/sync.proto\x12\x04sync\"*\n" +
-	"\vFileRequest\x12\x1b\n" +
-	"\tfile_name\x18\x01 \x01(\tR\bfileName\"K\n" +
-	"\rFileSignature\x12\x17\n" +
-	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12!\n" +
-	"\fchunk_hashes\x18\x02 \x03(\tR\vchunkHashes\">\n" +
-	"\x15MissingChunksResponse\x12%\n" +
-	"\x0emissing_hashes\x18\x01 \x03(\tR\rmissingHashes\"J\n" +
-	"\fChunkPayload\x12\x12\n" +
-	"\x04hash\x18\x01 \x01(\tR\x04hash\x12\x12\n" +
-	"\x04data\x18\x02 \x01(\fR\x04data\x12\x12\n" +
-	"\x04size\x18\x03 \x01(\x05R\x04size\"B\n" +
-	"\fUploadStatus\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage2\xc4\x01\n" +
-	"\tDeltaSync\x12D\n" +
-	"\x10GetMissingChunks\x12\x13.sync.FileSignature\x1a\x1b.sync.MissingChunksResponse\x128\n" +
-	"\fUploadChunks\x12\x12.sync.ChunkPayload\x1a\x12.sync.UploadStatus(\x01\x127\n" +
-	"\fDownloadFile\x12\x11.sync.FileRequest\x1a\x12.sync.ChunkPayload0\x01B\x16Z\x14delta-sync-pb/pkg/pbb\x06proto3"
+func (x *UploadStatus) GetChunksStored() int32 {
+	if x != nil {
+		return x.ChunksStored
+	}
+	return 0
+}
 
-var (
-	file_api_proto_sync_proto_rawDescOnce sync.Once
-	file_api_proto_sync_proto_rawDescData []byte
-)
+type CommitStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Version       int32                  `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`                                 // version the recipe was published as
+	MissingHashes []string               `protobuf:"bytes,4,rep,name=missing_hashes,json=missingHashes,proto3" json:"missing_hashes,omitempty"` // set when the commit was refused
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-func file_api_proto_sync_proto_rawDescGZIP() []byte {
-	file_api_proto_sync_proto_rawDescOnce.Do(func() {
-		file_api_proto_sync_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_proto_sync_proto_rawDesc), len(file_api_proto_sync_proto_rawDesc)))
-	})
-	return file_api_proto_sync_proto_rawDescData
+func (x *CommitStatus) Reset() {
+	*x = CommitStatus{}
+	mi := &file_api_proto_sync_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-var file_api_proto_sync_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
-var file_api_proto_sync_proto_goTypes = []any{
-	(*FileRequest)(nil),           // 0: sync.FileRequest
-	(*FileSignature)(nil),         // 1: sync.FileSignature
-	(*MissingChunksResponse)(nil), // 2: sync.MissingChunksResponse
-	(*ChunkPayload)(nil),          // 3: sync.ChunkPayload
-	(*UploadStatus)(nil),          // 4: sync.UploadStatus
+func (x *CommitStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
-var file_api_proto_sync_proto_depIdxs = []int32{
-	1, // 0: sync.DeltaSync.GetMissingChunks:input_type -> sync.FileSignature
-	3, // 1: sync.DeltaSync.UploadChunks:input_type -> sync.ChunkPayload
-	0, // 2: sync.DeltaSync.DownloadFile:input_type -> sync.FileRequest
-	2, // 3: sync.DeltaSync.GetMissingChunks:output_type -> sync.MissingChunksResponse
-	4, // 4: sync.DeltaSync.UploadChunks:output_type -> sync.UploadStatus
-	3, // 5: sync.DeltaSync.DownloadFile:output_type -> sync.ChunkPayload
-	3, // [3:6] is the sub-list for method output_type
-	0, // [0:3] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+
+func (*CommitStatus) ProtoMessage() {}
+
+func (x *CommitStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sync_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-func init() { file_api_proto_sync_proto_init() }
-func file_api_proto_sync_proto_init() {
-	if File_api_proto_sync_proto != nil {
-		return
+// Deprecated: Use CommitStatus.ProtoReflect.Descriptor instead.
+func (*CommitStatus) Descriptor() ([]byte, []int) {
+	return file_api_proto_sync_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CommitStatus) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *CommitStatus) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *CommitStatus) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *CommitStatus) GetMissingHashes() []string {
+	if x != nil {
+		return x.MissingHashes
+	}
+	return nil
+}
+
+type DeleteStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteStatus) Reset() {
+	*x = DeleteStatus{}
+	mi := &file_api_proto_sync_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteStatus) ProtoMessage() {}
+
+func (x *DeleteStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sync_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteStatus.ProtoReflect.Descriptor instead.
+func (*DeleteStatus) Descriptor() ([]byte, []int) {
+	return file_api_proto_sync_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeleteStatus) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DeleteStatus) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ListFilesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFilesRequest) Reset() {
+	*x = ListFilesRequest{}
+	mi := &file_api_proto_sync_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFilesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFilesRequest) ProtoMessage() {}
+
+func (x *ListFilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sync_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFilesRequest.ProtoReflect.Descriptor instead.
+func (*ListFilesRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_sync_proto_rawDescGZIP(), []int{9}
+}
+
+type FileInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileName      string                 `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	ChunkHashes   []string               `protobuf:"bytes,2,rep,name=chunk_hashes,json=chunkHashes,proto3" json:"chunk_hashes,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileInfo) Reset() {
+	*x = FileInfo{}
+	mi := &file_api_proto_sync_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileInfo) ProtoMessage() {}
+
+func (x *FileInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sync_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileInfo.ProtoReflect.Descriptor instead.
+func (*FileInfo) Descriptor() ([]byte, []int) {
+	return file_api_proto_sync_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *FileInfo) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *FileInfo) GetChunkHashes() []string {
+	if x != nil {
+		return x.ChunkHashes
+	}
+	return nil
+}
+
+func (x *FileInfo) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type FileList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         []*FileInfo            `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileList) Reset() {
+	*x = FileList{}
+	mi := &file_api_proto_sync_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileList) ProtoMessage() {}
+
+func (x *FileList) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sync_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileList.ProtoReflect.Descriptor instead.
+func (*FileList) Descriptor() ([]byte, []int) {
+	return file_api_proto_sync_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *FileList) GetFiles() []*FileInfo {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type ChunkRef struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hash          string                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Size          int32                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChunkRef) Reset() {
+	*x = ChunkRef{}
+	mi := &file_api_proto_sync_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChunkRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChunkRef) ProtoMessage() {}
+
+func (x *ChunkRef) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sync_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChunkRef.ProtoReflect.Descriptor instead.
+func (*ChunkRef) Descriptor() ([]byte, []int) {
+	return file_api_proto_sync_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ChunkRef) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *ChunkRef) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type Recipe struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileName      string                 `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	Chunks        []*ChunkRef            `protobuf:"bytes,2,rep,name=chunks,proto3" json:"chunks,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Version       int32                  `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Recipe) Reset() {
+	*x = Recipe{}
+	mi := &file_api_proto_sync_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Recipe) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Recipe) ProtoMessage() {}
+
+func (x *Recipe) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sync_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Recipe.ProtoReflect.Descriptor instead.
+func (*Recipe) Descriptor() ([]byte, []int) {
+	return file_api_proto_sync_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Recipe) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *Recipe) GetChunks() []*ChunkRef {
+	if x != nil {
+		return x.Chunks
+	}
+	return nil
+}
+
+func (x *Recipe) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Recipe) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type ChunkRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Hashes         []string               `protobuf:"bytes,1,rep,name=hashes,proto3" json:"hashes,omitempty"`
+	AcceptedCodecs []Codec                `protobuf:"varint,2,rep,packed,name=accepted_codecs,json=acceptedCodecs,proto3,enum=sync.Codec" json:"accepted_codecs,omitempty"` // chunks stored in one of these are sent without decompressing
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ChunkRequest) Reset() {
+	*x = ChunkRequest{}
+	mi := &file_api_proto_sync_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChunkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChunkRequest) ProtoMessage() {}
+
+func (x *ChunkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sync_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChunkRequest.ProtoReflect.Descriptor instead.
+func (*ChunkRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_sync_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ChunkRequest) GetHashes() []string {
+	if x != nil {
+		return x.Hashes
+	}
+	return nil
+}
+
+func (x *ChunkRequest) GetAcceptedCodecs() []Codec {
+	if x != nil {
+		return x.AcceptedCodecs
+	}
+	return nil
+}
+
+type FileVersion struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       int32                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	ChunkCount    int32                  `protobuf:"varint,4,opt,name=chunk_count,json=chunkCount,proto3" json:"chunk_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileVersion) Reset() {
+	*x = FileVersion{}
+	mi := &file_api_proto_sync_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileVersion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileVersion) ProtoMessage() {}
+
+func (x *FileVersion) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sync_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileVersion.ProtoReflect.Descriptor instead.
+func (*FileVersion) Descriptor() ([]byte, []int) {
+	return file_api_proto_sync_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *FileVersion) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *FileVersion) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *FileVersion) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FileVersion) GetChunkCount() int32 {
+	if x != nil {
+		return x.ChunkCount
+	}
+	return 0
+}
+
+type VersionList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileName      string                 `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	Versions      []*FileVersion         `protobuf:"bytes,2,rep,name=versions,proto3" json:"versions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VersionList) Reset() {
+	*x = VersionList{}
+	mi := &file_api_proto_sync_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VersionList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VersionList) ProtoMessage() {}
+
+func (x *VersionList) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sync_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VersionList.ProtoReflect.Descriptor instead.
+func (*VersionList) Descriptor() ([]byte, []int) {
+	return file_api_proto_sync_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *VersionList) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *VersionList) GetVersions() []*FileVersion {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+type UploadSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadSessionRequest) Reset() {
+	*x = UploadSessionRequest{}
+	mi := &file_api_proto_sync_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadSessionRequest) ProtoMessage() {}
+
+func (x *UploadSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sync_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadSessionRequest.ProtoReflect.Descriptor instead.
+func (*UploadSessionRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_sync_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *UploadSessionRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type UploadSessionStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	FileId        string                 `protobuf:"bytes,2,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	PendingHashes []string               `protobuf:"bytes,3,rep,name=pending_hashes,json=pendingHashes,proto3" json:"pending_hashes,omitempty"` // chunks of the session not yet committed, in upload order
+	Committed     int32                  `protobuf:"varint,4,opt,name=committed,proto3" json:"committed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadSessionStatus) Reset() {
+	*x = UploadSessionStatus{}
+	mi := &file_api_proto_sync_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadSessionStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadSessionStatus) ProtoMessage() {}
+
+func (x *UploadSessionStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sync_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadSessionStatus.ProtoReflect.Descriptor instead.
+func (*UploadSessionStatus) Descriptor() ([]byte, []int) {
+	return file_api_proto_sync_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *UploadSessionStatus) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *UploadSessionStatus) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *UploadSessionStatus) GetPendingHashes() []string {
+	if x != nil {
+		return x.PendingHashes
+	}
+	return nil
+}
+
+func (x *UploadSessionStatus) GetCommitted() int32 {
+	if x != nil {
+		return x.Committed
+	}
+	return 0
+}
+
+type WatchEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	mi := &file_api_proto_sync_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsRequest) ProtoMessage() {}
+
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sync_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_sync_proto_rawDescGZIP(), []int{19}
+}
+
+type SyncEvent struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	FileId string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	At     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=at,proto3" json:"at,omitempty"`
+	// Types that are valid to be assigned to Kind:
+	//
+	//	*SyncEvent_Started
+	//	*SyncEvent_ChunkStored
+	//	*SyncEvent_Completed
+	//	*SyncEvent_Failed
+	Kind          isSyncEvent_Kind `protobuf_oneof:"kind"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncEvent) Reset() {
+	*x = SyncEvent{}
+	mi := &file_api_proto_sync_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncEvent) ProtoMessage() {}
+
+func (x *SyncEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sync_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncEvent.ProtoReflect.Descriptor instead.
+func (*SyncEvent) Descriptor() ([]byte, []int) {
+	return file_api_proto_sync_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SyncEvent) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *SyncEvent) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+func (x *SyncEvent) GetKind() isSyncEvent_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return nil
+}
+
+func (x *SyncEvent) GetStarted() *SyncStartedEvent {
+	if x != nil {
+		if x, ok := x.Kind.(*SyncEvent_Started); ok {
+			return x.Started
+		}
+	}
+	return nil
+}
+
+func (x *SyncEvent) GetChunkStored() *ChunkStoredEvent {
+	if x != nil {
+		if x, ok := x.Kind.(*SyncEvent_ChunkStored); ok {
+			return x.ChunkStored
+		}
+	}
+	return nil
+}
+
+func (x *SyncEvent) GetCompleted() *SyncCompletedEvent {
+	if x != nil {
+		if x, ok := x.Kind.(*SyncEvent_Completed); ok {
+			return x.Completed
+		}
+	}
+	return nil
+}
+
+func (x *SyncEvent) GetFailed() *SyncFailedEvent {
+	if x != nil {
+		if x, ok := x.Kind.(*SyncEvent_Failed); ok {
+			return x.Failed
+		}
+	}
+	return nil
+}
+
+type isSyncEvent_Kind interface {
+	isSyncEvent_Kind()
+}
+
+type SyncEvent_Started struct {
+	Started *SyncStartedEvent `protobuf:"bytes,3,opt,name=started,proto3,oneof"`
+}
+
+type SyncEvent_ChunkStored struct {
+	ChunkStored *ChunkStoredEvent `protobuf:"bytes,4,opt,name=chunk_stored,json=chunkStored,proto3,oneof"`
+}
+
+type SyncEvent_Completed struct {
+	Completed *SyncCompletedEvent `protobuf:"bytes,5,opt,name=completed,proto3,oneof"`
+}
+
+type SyncEvent_Failed struct {
+	Failed *SyncFailedEvent `protobuf:"bytes,6,opt,name=failed,proto3,oneof"`
+}
+
+func (*SyncEvent_Started) isSyncEvent_Kind() {}
+
+func (*SyncEvent_ChunkStored) isSyncEvent_Kind() {}
+
+func (*SyncEvent_Completed) isSyncEvent_Kind() {}
+
+func (*SyncEvent_Failed) isSyncEvent_Kind() {}
+
+type SyncStartedEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChunkCount    int32                  `protobuf:"varint,1,opt,name=chunk_count,json=chunkCount,proto3" json:"chunk_count,omitempty"`
+	TotalBytes    int64                  `protobuf:"varint,2,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncStartedEvent) Reset() {
+	*x = SyncStartedEvent{}
+	mi := &file_api_proto_sync_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncStartedEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncStartedEvent) ProtoMessage() {}
+
+func (x *SyncStartedEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sync_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncStartedEvent.ProtoReflect.Descriptor instead.
+func (*SyncStartedEvent) Descriptor() ([]byte, []int) {
+	return file_api_proto_sync_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *SyncStartedEvent) GetChunkCount() int32 {
+	if x != nil {
+		return x.ChunkCount
+	}
+	return 0
+}
+
+func (x *SyncStartedEvent) GetTotalBytes() int64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+type ChunkStoredEvent struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Hash           string                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Size           int32                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	ChunksDone     int32                  `protobuf:"varint,3,opt,name=chunks_done,json=chunksDone,proto3" json:"chunks_done,omitempty"`
+	ChunksTotal    int32                  `protobuf:"varint,4,opt,name=chunks_total,json=chunksTotal,proto3" json:"chunks_total,omitempty"`
+	BytesDone      int64                  `protobuf:"varint,5,opt,name=bytes_done,json=bytesDone,proto3" json:"bytes_done,omitempty"`
+	BytesTotal     int64                  `protobuf:"varint,6,opt,name=bytes_total,json=bytesTotal,proto3" json:"bytes_total,omitempty"`
+	BytesPerSecond float64                `protobuf:"fixed64,7,opt,name=bytes_per_second,json=bytesPerSecond,proto3" json:"bytes_per_second,omitempty"`
+	EtaSeconds     float64                `protobuf:"fixed64,8,opt,name=eta_seconds,json=etaSeconds,proto3" json:"eta_seconds,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ChunkStoredEvent) Reset() {
+	*x = ChunkStoredEvent{}
+	mi := &file_api_proto_sync_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChunkStoredEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChunkStoredEvent) ProtoMessage() {}
+
+func (x *ChunkStoredEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sync_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChunkStoredEvent.ProtoReflect.Descriptor instead.
+func (*ChunkStoredEvent) Descriptor() ([]byte, []int) {
+	return file_api_proto_sync_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ChunkStoredEvent) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *ChunkStoredEvent) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *ChunkStoredEvent) GetChunksDone() int32 {
+	if x != nil {
+		return x.ChunksDone
+	}
+	return 0
+}
+
+func (x *ChunkStoredEvent) GetChunksTotal() int32 {
+	if x != nil {
+		return x.ChunksTotal
+	}
+	return 0
+}
+
+func (x *ChunkStoredEvent) GetBytesDone() int64 {
+	if x != nil {
+		return x.BytesDone
+	}
+	return 0
+}
+
+func (x *ChunkStoredEvent) GetBytesTotal() int64 {
+	if x != nil {
+		return x.BytesTotal
+	}
+	return 0
+}
+
+func (x *ChunkStoredEvent) GetBytesPerSecond() float64 {
+	if x != nil {
+		return x.BytesPerSecond
+	}
+	return 0
+}
+
+func (x *ChunkStoredEvent) GetEtaSeconds() float64 {
+	if x != nil {
+		return x.EtaSeconds
+	}
+	return 0
+}
+
+type SyncCompletedEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChunkCount    int32                  `protobuf:"varint,1,opt,name=chunk_count,json=chunkCount,proto3" json:"chunk_count,omitempty"`
+	TotalBytes    int64                  `protobuf:"varint,2,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`
+	Seconds       float64                `protobuf:"fixed64,3,opt,name=seconds,proto3" json:"seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncCompletedEvent) Reset() {
+	*x = SyncCompletedEvent{}
+	mi := &file_api_proto_sync_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncCompletedEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncCompletedEvent) ProtoMessage() {}
+
+func (x *SyncCompletedEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sync_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncCompletedEvent.ProtoReflect.Descriptor instead.
+func (*SyncCompletedEvent) Descriptor() ([]byte, []int) {
+	return file_api_proto_sync_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SyncCompletedEvent) GetChunkCount() int32 {
+	if x != nil {
+		return x.ChunkCount
+	}
+	return 0
+}
+
+func (x *SyncCompletedEvent) GetTotalBytes() int64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+func (x *SyncCompletedEvent) GetSeconds() float64 {
+	if x != nil {
+		return x.Seconds
+	}
+	return 0
+}
+
+type SyncFailedEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         string                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	ChunksDone    int32                  `protobuf:"varint,2,opt,name=chunks_done,json=chunksDone,proto3" json:"chunks_done,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncFailedEvent) Reset() {
+	*x = SyncFailedEvent{}
+	mi := &file_api_proto_sync_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncFailedEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncFailedEvent) ProtoMessage() {}
+
+func (x *SyncFailedEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sync_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncFailedEvent.ProtoReflect.Descriptor instead.
+func (*SyncFailedEvent) Descriptor() ([]byte, []int) {
+	return file_api_proto_sync_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *SyncFailedEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *SyncFailedEvent) GetChunksDone() int32 {
+	if x != nil {
+		return x.ChunksDone
+	}
+	return 0
+}
+
+var File_api_proto_sync_proto protoreflect.FileDescriptor
+
+const file_api_proto_sync_proto_rawDesc = "" +
+	"\n" +
+	"\x14api/proto/sync.proto\x12\x04sync\x1a\x1fgoogle/protobuf/timestamp.proto\"D\n" +
+	"\vFileRequest\x12\x1b\n" +
+	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x05R\aversion\"\x9e\x01\n" +
+	"\rFileSignature\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12!\n" +
+	"\fchunk_hashes\x18\x02 \x03(\tR\vchunkHashes\x12\x1b\n" +
+	"\tfile_size\x18\x03 \x01(\x03R\bfileSize\x124\n" +
+	"\x0faccepted_codecs\x18\x04 \x03(\x0e2\v.sync.CodecR\x0eacceptedCodecs\"\x80\x01\n" +
+	"\x15MissingChunksResponse\x12%\n" +
+	"\x0emissing_hashes\x18\x01 \x03(\tR\rmissingHashes\x12!\n" +
+	"\x05codec\x18\x02 \x01(\x0e2\v.sync.CodecR\x05codec\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x03 \x01(\tR\tsessionId\"s\n" +
+	"\fChunkPayload\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\tR\x04hash\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x05R\x04size\x12!\n" +
+	"\x05codec\x18\x04 \x01(\x0e2\v.sync.CodecR\x05codecJ\x04\b\x05\x10\x06\"\x88\x01\n" +
+	"\fUploadHeader\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x12\x1f\n" +
+	"\vchunk_count\x18\x03 \x01(\x05R\n" +
+	"chunkCount\x12\x1f\n" +
+	"\vtotal_bytes\x18\x04 \x01(\x03R\n" +
+	"totalBytes\"t\n" +
+	"\rUploadRequest\x12,\n" +
+	"\x06header\x18\x01 \x01(\v2\x12.sync.UploadHeaderH\x00R\x06header\x12*\n" +
+	"\x05chunk\x18\x02 \x01(\v2\x12.sync.ChunkPayloadH\x00R\x05chunkB\t\n" +
+	"\apayload\"\x88\x01\n" +
+	"\fUploadStatus\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1f\n" +
+	"\vfailed_hash\x18\x03 \x01(\tR\n" +
+	"failedHash\x12#\n" +
+	"\rchunks_stored\x18\x04 \x01(\x05R\fchunksStored\"\x83\x01\n" +
+	"\fCommitStatus\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x05R\aversion\x12%\n" +
+	"\x0emissing_hashes\x18\x04 \x03(\tR\rmissingHashes\"B\n" +
+	"\fDeleteStatus\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x12\n" +
+	"\x10ListFilesRequest\"\x85\x01\n" +
+	"\bFileInfo\x12\x1b\n" +
+	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12!\n" +
+	"\fchunk_hashes\x18\x02 \x03(\tR\vchunkHashes\x129\n" +
+	"\n" +
+	"updated_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"0\n" +
+	"\bFileList\x12$\n" +
+	"\x05files\x18\x01 \x03(\v2\x0e.sync.FileInfoR\x05files\"2\n" +
+	"\bChunkRef\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\tR\x04hash\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x05R\x04size\"\xa2\x01\n" +
+	"\x06Recipe\x12\x1b\n" +
+	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12&\n" +
+	"\x06chunks\x18\x02 \x03(\v2\x0e.sync.ChunkRefR\x06chunks\x129\n" +
+	"\n" +
+	"updated_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\x05R\aversion\"\\\n" +
+	"\fChunkRequest\x12\x16\n" +
+	"\x06hashes\x18\x01 \x03(\tR\x06hashes\x124\n" +
+	"\x0faccepted_codecs\x18\x02 \x03(\x0e2\v.sync.CodecR\x0eacceptedCodecs\"\x97\x01\n" +
+	"\vFileVersion\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x05R\aversion\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x1f\n" +
+	"\vchunk_count\x18\x04 \x01(\x05R\n" +
+	"chunkCount\"Y\n" +
+	"\vVersionList\x12\x1b\n" +
+	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12-\n" +
+	"\bversions\x18\x02 \x03(\v2\x11.sync.FileVersionR\bversions\"5\n" +
+	"\x14UploadSessionRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"\x92\x01\n" +
+	"\x13UploadSessionStatus\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x17\n" +
+	"\afile_id\x18\x02 \x01(\tR\x06fileId\x12%\n" +
+	"\x0epending_hashes\x18\x03 \x03(\tR\rpendingHashes\x12\x1c\n" +
+	"\tcommitted\x18\x04 \x01(\x05R\tcommitted\"\x14\n" +
+	"\x12WatchEventsRequest\"\xb4\x02\n" +
+	"\tSyncEvent\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12*\n" +
+	"\x02at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02at\x122\n" +
+	"\astarted\x18\x03 \x01(\v2\x16.sync.SyncStartedEventH\x00R\astarted\x12;\n" +
+	"\fchunk_stored\x18\x04 \x01(\v2\x16.sync.ChunkStoredEventH\x00R\vchunkStored\x128\n" +
+	"\tcompleted\x18\x05 \x01(\v2\x18.sync.SyncCompletedEventH\x00R\tcompleted\x12/\n" +
+	"\x06failed\x18\x06 \x01(\v2\x15.sync.SyncFailedEventH\x00R\x06failedB\x06\n" +
+	"\x04kind\"T\n" +
+	"\x10SyncStartedEvent\x12\x1f\n" +
+	"\vchunk_count\x18\x01 \x01(\x05R\n" +
+	"chunkCount\x12\x1f\n" +
+	"\vtotal_bytes\x18\x02 \x01(\x03R\n" +
+	"totalBytes\"\x89\x02\n" +
+	"\x10ChunkStoredEvent\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\tR\x04hash\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x05R\x04size\x12\x1f\n" +
+	"\vchunks_done\x18\x03 \x01(\x05R\n" +
+	"chunksDone\x12!\n" +
+	"\fchunks_total\x18\x04 \x01(\x05R\vchunksTotal\x12\x1d\n" +
+	"\n" +
+	"bytes_done\x18\x05 \x01(\x03R\tbytesDone\x12\x1f\n" +
+	"\vbytes_total\x18\x06 \x01(\x03R\n" +
+	"bytesTotal\x12(\n" +
+	"\x10bytes_per_second\x18\a \x01(\x01R\x0ebytesPerSecond\x12\x1f\n" +
+	"\veta_seconds\x18\b \x01(\x01R\n" +
+	"etaSeconds\"p\n" +
+	"\x12SyncCompletedEvent\x12\x1f\n" +
+	"\vchunk_count\x18\x01 \x01(\x05R\n" +
+	"chunkCount\x12\x1f\n" +
+	"\vtotal_bytes\x18\x02 \x01(\x03R\n" +
+	"totalBytes\x12\x18\n" +
+	"\aseconds\x18\x03 \x01(\x01R\aseconds\"H\n" +
+	"\x0fSyncFailedEvent\x12\x14\n" +
+	"\x05error\x18\x01 \x01(\tR\x05error\x12\x1f\n" +
+	"\vchunks_done\x18\x02 \x01(\x05R\n" +
+	"chunksDone*9\n" +
+	"\x05Codec\x12\x10\n" +
+	"\fCODEC_STORED\x10\x00\x12\x0e\n" +
+	"\n" +
+	"CODEC_GZIP\x10\x01\x12\x0e\n" +
+	"\n" +
+	"CODEC_ZSTD\x10\x022\x8a\x05\n" +
+	"\tDeltaSync\x12D\n" +
+	"\x10GetMissingChunks\x12\x13.sync.FileSignature\x1a\x1b.sync.MissingChunksResponse\x129\n" +
+	"\fUploadChunks\x12\x13.sync.UploadRequest\x1a\x12.sync.UploadStatus(\x01\x125\n" +
+	"\n" +
+	"CommitFile\x12\x13.sync.FileSignature\x1a\x12.sync.CommitStatus\x127\n" +
+	"\fDownloadFile\x12\x11.sync.FileRequest\x1a\x12.sync.ChunkPayload0\x01\x123\n" +
+	"\n" +
+	"DeleteFile\x12\x11.sync.FileRequest\x1a\x12.sync.DeleteStatus\x123\n" +
+	"\tListFiles\x12\x16.sync.ListFilesRequest\x1a\x0e.sync.FileList\x12,\n" +
+	"\tGetRecipe\x12\x11.sync.FileRequest\x1a\f.sync.Recipe\x127\n" +
+	"\vFetchChunks\x12\x12.sync.ChunkRequest\x1a\x12.sync.ChunkPayload0\x01\x124\n" +
+	"\fListVersions\x12\x11.sync.FileRequest\x1a\x11.sync.VersionList\x12I\n" +
+	"\x10GetUploadSession\x12\x1a.sync.UploadSessionRequest\x1a\x19.sync.UploadSessionStatus\x12:\n" +
+	"\vWatchEvents\x12\x18.sync.WatchEventsRequest\x1a\x0f.sync.SyncEvent0\x01B\x16Z\x14delta-sync-pb/pkg/pbb\x06proto3"
+
+var (
+	file_api_proto_sync_proto_rawDescOnce sync.Once
+	file_api_proto_sync_proto_rawDescData []byte
+)
+
+func file_api_proto_sync_proto_rawDescGZIP() []byte {
+	file_api_proto_sync_proto_rawDescOnce.Do(func() {
+		file_api_proto_sync_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_proto_sync_proto_rawDesc), len(file_api_proto_sync_proto_rawDesc)))
+	})
+	return file_api_proto_sync_proto_rawDescData
+}
+
+var file_api_proto_sync_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_api_proto_sync_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
+var file_api_proto_sync_proto_goTypes = []any{
+	(Codec)(0),                    // 0: sync.Codec
+	(*FileRequest)(nil),           // 1: sync.FileRequest
+	(*FileSignature)(nil),         // 2: sync.FileSignature
+	(*MissingChunksResponse)(nil), // 3: sync.MissingChunksResponse
+	(*ChunkPayload)(nil),          // 4: sync.ChunkPayload
+	(*UploadHeader)(nil),          // 5: sync.UploadHeader
+	(*UploadRequest)(nil),         // 6: sync.UploadRequest
+	(*UploadStatus)(nil),          // 7: sync.UploadStatus
+	(*CommitStatus)(nil),          // 8: sync.CommitStatus
+	(*DeleteStatus)(nil),          // 9: sync.DeleteStatus
+	(*ListFilesRequest)(nil),      // 10: sync.ListFilesRequest
+	(*FileInfo)(nil),              // 11: sync.FileInfo
+	(*FileList)(nil),              // 12: sync.FileList
+	(*ChunkRef)(nil),              // 13: sync.ChunkRef
+	(*Recipe)(nil),                // 14: sync.Recipe
+	(*ChunkRequest)(nil),          // 15: sync.ChunkRequest
+	(*FileVersion)(nil),           // 16: sync.FileVersion
+	(*VersionList)(nil),           // 17: sync.VersionList
+	(*UploadSessionRequest)(nil),  // 18: sync.UploadSessionRequest
+	(*UploadSessionStatus)(nil),   // 19: sync.UploadSessionStatus
+	(*WatchEventsRequest)(nil),    // 20: sync.WatchEventsRequest
+	(*SyncEvent)(nil),             // 21: sync.SyncEvent
+	(*SyncStartedEvent)(nil),      // 22: sync.SyncStartedEvent
+	(*ChunkStoredEvent)(nil),      // 23: sync.ChunkStoredEvent
+	(*SyncCompletedEvent)(nil),    // 24: sync.SyncCompletedEvent
+	(*SyncFailedEvent)(nil),       // 25: sync.SyncFailedEvent
+	(*timestamppb.Timestamp)(nil), // 26: google.protobuf.Timestamp
+}
+var file_api_proto_sync_proto_depIdxs = []int32{
+	0,  // 0: sync.FileSignature.accepted_codecs:type_name -> sync.Codec
+	0,  // 1: sync.MissingChunksResponse.codec:type_name -> sync.Codec
+	0,  // 2: sync.ChunkPayload.codec:type_name -> sync.Codec
+	5,  // 3: sync.UploadRequest.header:type_name -> sync.UploadHeader
+	4,  // 4: sync.UploadRequest.chunk:type_name -> sync.ChunkPayload
+	26, // 5: sync.FileInfo.updated_at:type_name -> google.protobuf.Timestamp
+	11, // 6: sync.FileList.files:type_name -> sync.FileInfo
+	13, // 7: sync.Recipe.chunks:type_name -> sync.ChunkRef
+	26, // 8: sync.Recipe.updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 9: sync.ChunkRequest.accepted_codecs:type_name -> sync.Codec
+	26, // 10: sync.FileVersion.created_at:type_name -> google.protobuf.Timestamp
+	16, // 11: sync.VersionList.versions:type_name -> sync.FileVersion
+	26, // 12: sync.SyncEvent.at:type_name -> google.protobuf.Timestamp
+	22, // 13: sync.SyncEvent.started:type_name -> sync.SyncStartedEvent
+	23, // 14: sync.SyncEvent.chunk_stored:type_name -> sync.ChunkStoredEvent
+	24, // 15: sync.SyncEvent.completed:type_name -> sync.SyncCompletedEvent
+	25, // 16: sync.SyncEvent.failed:type_name -> sync.SyncFailedEvent
+	2,  // 17: sync.DeltaSync.GetMissingChunks:input_type -> sync.FileSignature
+	6,  // 18: sync.DeltaSync.UploadChunks:input_type -> sync.UploadRequest
+	2,  // 19: sync.DeltaSync.CommitFile:input_type -> sync.FileSignature
+	1,  // 20: sync.DeltaSync.DownloadFile:input_type -> sync.FileRequest
+	1,  // 21: sync.DeltaSync.DeleteFile:input_type -> sync.FileRequest
+	10, // 22: sync.DeltaSync.ListFiles:input_type -> sync.ListFilesRequest
+	1,  // 23: sync.DeltaSync.GetRecipe:input_type -> sync.FileRequest
+	15, // 24: sync.DeltaSync.FetchChunks:input_type -> sync.ChunkRequest
+	1,  // 25: sync.DeltaSync.ListVersions:input_type -> sync.FileRequest
+	18, // 26: sync.DeltaSync.GetUploadSession:input_type -> sync.UploadSessionRequest
+	20, // 27: sync.DeltaSync.WatchEvents:input_type -> sync.WatchEventsRequest
+	3,  // 28: sync.DeltaSync.GetMissingChunks:output_type -> sync.MissingChunksResponse
+	7,  // 29: sync.DeltaSync.UploadChunks:output_type -> sync.UploadStatus
+	8,  // 30: sync.DeltaSync.CommitFile:output_type -> sync.CommitStatus
+	4,  // 31: sync.DeltaSync.DownloadFile:output_type -> sync.ChunkPayload
+	9,  // 32: sync.DeltaSync.DeleteFile:output_type -> sync.DeleteStatus
+	12, // 33: sync.DeltaSync.ListFiles:output_type -> sync.FileList
+	14, // 34: sync.DeltaSync.GetRecipe:output_type -> sync.Recipe
+	4,  // 35: sync.DeltaSync.FetchChunks:output_type -> sync.ChunkPayload
+	17, // 36: sync.DeltaSync.ListVersions:output_type -> sync.VersionList
+	19, // 37: sync.DeltaSync.GetUploadSession:output_type -> sync.UploadSessionStatus
+	21, // 38: sync.DeltaSync.WatchEvents:output_type -> sync.SyncEvent
+	28, // [28:39] is the sub-list for method output_type
+	17, // [17:28] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
+}
+
+func init() { file_api_proto_sync_proto_init() }
+func file_api_proto_sync_proto_init() {
+	if File_api_proto_sync_proto != nil {
+		return
+	}
+	file_api_proto_sync_proto_msgTypes[5].OneofWrappers = []any{
+		(*UploadRequest_Header)(nil),
+		(*UploadRequest_Chunk)(nil),
+	}
+	file_api_proto_sync_proto_msgTypes[20].OneofWrappers = []any{
+		(*SyncEvent_Started)(nil),
+		(*SyncEvent_ChunkStored)(nil),
+		(*SyncEvent_Completed)(nil),
+		(*SyncEvent_Failed)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_sync_proto_rawDesc), len(file_api_proto_sync_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   5,
+			NumEnums:      1,
+			NumMessages:   25,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_api_proto_sync_proto_goTypes,
 		DependencyIndexes: file_api_proto_sync_proto_depIdxs,
+		EnumInfos:         file_api_proto_sync_proto_enumTypes,
 		MessageInfos:      file_api_proto_sync_proto_msgTypes,
 	}.Build()
 	File_api_proto_sync_proto = out.File
@@ -21,7 +21,15 @@ const _ = grpc.SupportPackageIsVersion9
 const (
 	DeltaSync_GetMissingChunks_FullMethodName = "/sync.DeltaSync/GetMissingChunks"
 	DeltaSync_UploadChunks_FullMethodName     = "/sync.DeltaSync/UploadChunks"
+	DeltaSync_CommitFile_FullMethodName       = "/sync.DeltaSync/CommitFile"
 	DeltaSync_DownloadFile_FullMethodName     = "/sync.DeltaSync/DownloadFile"
+	DeltaSync_DeleteFile_FullMethodName       = "/sync.DeltaSync/DeleteFile"
+	DeltaSync_ListFiles_FullMethodName        = "/sync.DeltaSync/ListFiles"
+	DeltaSync_GetRecipe_FullMethodName        = "/sync.DeltaSync/GetRecipe"
+	DeltaSync_FetchChunks_FullMethodName      = "/sync.DeltaSync/FetchChunks"
+	DeltaSync_ListVersions_FullMethodName     = "/sync.DeltaSync/ListVersions"
+	DeltaSync_GetUploadSession_FullMethodName = "/sync.DeltaSync/GetUploadSession"
+	DeltaSync_WatchEvents_FullMethodName      = "/sync.DeltaSync/WatchEvents"
 )
 
 // DeltaSyncClient is the client API for DeltaSync service.
@@ -30,10 +38,28 @@ const (
 type DeltaSyncClient interface {
 	// client sends a list of hashes; server returns only the ones it DOES NOT have
 	GetMissingChunks(ctx context.Context, in *FileSignature, opts ...grpc.CallOption) (*MissingChunksResponse, error)
-	// Clinet streams the actual raw bytes of those missing chunks to the server
-	UploadChunks(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ChunkPayload, UploadStatus], error)
+	// Clinet streams the actual raw bytes of those missing chunks to the server,
+	// announcing them with an UploadHeader first
+	UploadChunks(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadRequest, UploadStatus], error)
+	// publishes the file's recipe once every chunk it references is stored; otherwise nothing
+	// changes and the still-missing hashes are returned
+	CommitFile(ctx context.Context, in *FileSignature, opts ...grpc.CallOption) (*CommitStatus, error)
 	// allows a user to request a file for reconstruction
 	DownloadFile(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChunkPayload], error)
+	// removes a file from the registry after it was deleted or renamed on the client
+	DeleteFile(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*DeleteStatus, error)
+	// lists every file in the registry so clients can pull newer versions down
+	ListFiles(ctx context.Context, in *ListFilesRequest, opts ...grpc.CallOption) (*FileList, error)
+	// returns the ordered chunk list of a file so the client can diff it against its local chunks
+	GetRecipe(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*Recipe, error)
+	// streams only the requested chunks; the mirror image of GetMissingChunks for downloads
+	FetchChunks(ctx context.Context, in *ChunkRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChunkPayload], error)
+	// lists every stored version of a file, newest first
+	ListVersions(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*VersionList, error)
+	// reports which chunks of an interrupted upload still have to be sent
+	GetUploadSession(ctx context.Context, in *UploadSessionRequest, opts ...grpc.CallOption) (*UploadSessionStatus, error)
+	// streams the caller's sync events, e.g. to a dashboard running in another process
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SyncEvent], error)
 }
 
 type deltaSyncClient struct {
@@ -54,18 +80,28 @@ func (c *deltaSyncClient) GetMissingChunks(ctx context.Context, in *FileSignatur
 	return out, nil
 }
 
-func (c *deltaSyncClient) UploadChunks(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ChunkPayload, UploadStatus], error) {
+func (c *deltaSyncClient) UploadChunks(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadRequest, UploadStatus], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	stream, err := c.cc.NewStream(ctx, &DeltaSync_ServiceDesc.Streams[0], DeltaSync_UploadChunks_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &grpc.GenericClientStream[ChunkPayload, UploadStatus]{ClientStream: stream}
+	x := &grpc.GenericClientStream[UploadRequest, UploadStatus]{ClientStream: stream}
 	return x, nil
 }
 
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
-type DeltaSync_UploadChunksClient = grpc.ClientStreamingClient[ChunkPayload, UploadStatus]
+type DeltaSync_UploadChunksClient = grpc.ClientStreamingClient[UploadRequest, UploadStatus]
+
+func (c *deltaSyncClient) CommitFile(ctx context.Context, in *FileSignature, opts ...grpc.CallOption) (*CommitStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CommitStatus)
+	err := c.cc.Invoke(ctx, DeltaSync_CommitFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
 
 func (c *deltaSyncClient) DownloadFile(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChunkPayload], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
@@ -86,16 +122,122 @@ func (c *deltaSyncClient) DownloadFile(ctx context.Context, in *FileRequest, opt
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type DeltaSync_DownloadFileClient = grpc.ServerStreamingClient[ChunkPayload]
 
+func (c *deltaSyncClient) DeleteFile(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*DeleteStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteStatus)
+	err := c.cc.Invoke(ctx, DeltaSync_DeleteFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deltaSyncClient) ListFiles(ctx context.Context, in *ListFilesRequest, opts ...grpc.CallOption) (*FileList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileList)
+	err := c.cc.Invoke(ctx, DeltaSync_ListFiles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deltaSyncClient) GetRecipe(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*Recipe, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Recipe)
+	err := c.cc.Invoke(ctx, DeltaSync_GetRecipe_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deltaSyncClient) FetchChunks(ctx context.Context, in *ChunkRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChunkPayload], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DeltaSync_ServiceDesc.Streams[2], DeltaSync_FetchChunks_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ChunkRequest, ChunkPayload]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DeltaSync_FetchChunksClient = grpc.ServerStreamingClient[ChunkPayload]
+
+func (c *deltaSyncClient) ListVersions(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*VersionList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VersionList)
+	err := c.cc.Invoke(ctx, DeltaSync_ListVersions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deltaSyncClient) GetUploadSession(ctx context.Context, in *UploadSessionRequest, opts ...grpc.CallOption) (*UploadSessionStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UploadSessionStatus)
+	err := c.cc.Invoke(ctx, DeltaSync_GetUploadSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deltaSyncClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SyncEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DeltaSync_ServiceDesc.Streams[3], DeltaSync_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchEventsRequest, SyncEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DeltaSync_WatchEventsClient = grpc.ServerStreamingClient[SyncEvent]
+
 // DeltaSyncServer is the server API for DeltaSync service.
 // All implementations must embed UnimplementedDeltaSyncServer
 // for forward compatibility.
 type DeltaSyncServer interface {
 	// client sends a list of hashes; server returns only the ones it DOES NOT have
 	GetMissingChunks(context.Context, *FileSignature) (*MissingChunksResponse, error)
-	// Clinet streams the actual raw bytes of those missing chunks to the server
-	UploadChunks(grpc.ClientStreamingServer[ChunkPayload, UploadStatus]) error
+	// Clinet streams the actual raw bytes of those missing chunks to the server,
+	// announcing them with an UploadHeader first
+	UploadChunks(grpc.ClientStreamingServer[UploadRequest, UploadStatus]) error
+	// publishes the file's recipe once every chunk it references is stored; otherwise nothing
+	// changes and the still-missing hashes are returned
+	CommitFile(context.Context, *FileSignature) (*CommitStatus, error)
 	// allows a user to request a file for reconstruction
 	DownloadFile(*FileRequest, grpc.ServerStreamingServer[ChunkPayload]) error
+	// removes a file from the registry after it was deleted or renamed on the client
+	DeleteFile(context.Context, *FileRequest) (*DeleteStatus, error)
+	// lists every file in the registry so clients can pull newer versions down
+	ListFiles(context.Context, *ListFilesRequest) (*FileList, error)
+	// returns the ordered chunk list of a file so the client can diff it against its local chunks
+	GetRecipe(context.Context, *FileRequest) (*Recipe, error)
+	// streams only the requested chunks; the mirror image of GetMissingChunks for downloads
+	FetchChunks(*ChunkRequest, grpc.ServerStreamingServer[ChunkPayload]) error
+	// lists every stored version of a file, newest first
+	ListVersions(context.Context, *FileRequest) (*VersionList, error)
+	// reports which chunks of an interrupted upload still have to be sent
+	GetUploadSession(context.Context, *UploadSessionRequest) (*UploadSessionStatus, error)
+	// streams the caller's sync events, e.g. to a dashboard running in another process
+	WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[SyncEvent]) error
 	mustEmbedUnimplementedDeltaSyncServer()
 }
 
@@ -109,12 +251,36 @@ type UnimplementedDeltaSyncServer struct{}
 func (UnimplementedDeltaSyncServer) GetMissingChunks(context.Context, *FileSignature) (*MissingChunksResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetMissingChunks not implemented")
 }
-func (UnimplementedDeltaSyncServer) UploadChunks(grpc.ClientStreamingServer[ChunkPayload, UploadStatus]) error {
+func (UnimplementedDeltaSyncServer) UploadChunks(grpc.ClientStreamingServer[UploadRequest, UploadStatus]) error {
 	return status.Error(codes.Unimplemented, "method UploadChunks not implemented")
 }
+func (UnimplementedDeltaSyncServer) CommitFile(context.Context, *FileSignature) (*CommitStatus, error) {
+	return nil, status.Error(codes.Unimplemented, "method CommitFile not implemented")
+}
 func (UnimplementedDeltaSyncServer) DownloadFile(*FileRequest, grpc.ServerStreamingServer[ChunkPayload]) error {
 	return status.Error(codes.Unimplemented, "method DownloadFile not implemented")
 }
+func (UnimplementedDeltaSyncServer) DeleteFile(context.Context, *FileRequest) (*DeleteStatus, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteFile not implemented")
+}
+func (UnimplementedDeltaSyncServer) ListFiles(context.Context, *ListFilesRequest) (*FileList, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListFiles not implemented")
+}
+func (UnimplementedDeltaSyncServer) GetRecipe(context.Context, *FileRequest) (*Recipe, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetRecipe not implemented")
+}
+func (UnimplementedDeltaSyncServer) FetchChunks(*ChunkRequest, grpc.ServerStreamingServer[ChunkPayload]) error {
+	return status.Error(codes.Unimplemented, "method FetchChunks not implemented")
+}
+func (UnimplementedDeltaSyncServer) ListVersions(context.Context, *FileRequest) (*VersionList, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListVersions not implemented")
+}
+func (UnimplementedDeltaSyncServer) GetUploadSession(context.Context, *UploadSessionRequest) (*UploadSessionStatus, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetUploadSession not implemented")
+}
+func (UnimplementedDeltaSyncServer) WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[SyncEvent]) error {
+	return status.Error(codes.Unimplemented, "method WatchEvents not implemented")
+}
 func (UnimplementedDeltaSyncServer) mustEmbedUnimplementedDeltaSyncServer() {}
 func (UnimplementedDeltaSyncServer) testEmbeddedByValue()                   {}
 
@@ -155,11 +321,29 @@ func _DeltaSync_GetMissingChunks_Handler(srv interface{}, ctx context.Context, d
 }
 
 func _DeltaSync_UploadChunks_Handler(srv interface{}, stream grpc.ServerStream) error {
-	return srv.(DeltaSyncServer).UploadChunks(&grpc.GenericServerStream[ChunkPayload, UploadStatus]{ServerStream: stream})
+	return srv.(DeltaSyncServer).UploadChunks(&grpc.GenericServerStream[UploadRequest, UploadStatus]{ServerStream: stream})
 }
 
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
-type DeltaSync_UploadChunksServer = grpc.ClientStreamingServer[ChunkPayload, UploadStatus]
+type DeltaSync_UploadChunksServer = grpc.ClientStreamingServer[UploadRequest, UploadStatus]
+
+func _DeltaSync_CommitFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileSignature)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeltaSyncServer).CommitFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeltaSync_CommitFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeltaSyncServer).CommitFile(ctx, req.(*FileSignature))
+	}
+	return interceptor(ctx, in, info, handler)
+}
 
 func _DeltaSync_DownloadFile_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(FileRequest)
@@ -172,6 +356,118 @@ func _DeltaSync_DownloadFile_Handler(srv interface{}, stream grpc.ServerStream)
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type DeltaSync_DownloadFileServer = grpc.ServerStreamingServer[ChunkPayload]
 
+func _DeltaSync_DeleteFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeltaSyncServer).DeleteFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeltaSync_DeleteFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeltaSyncServer).DeleteFile(ctx, req.(*FileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeltaSync_ListFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFilesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeltaSyncServer).ListFiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeltaSync_ListFiles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeltaSyncServer).ListFiles(ctx, req.(*ListFilesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeltaSync_GetRecipe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeltaSyncServer).GetRecipe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeltaSync_GetRecipe_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeltaSyncServer).GetRecipe(ctx, req.(*FileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeltaSync_FetchChunks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ChunkRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DeltaSyncServer).FetchChunks(m, &grpc.GenericServerStream[ChunkRequest, ChunkPayload]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DeltaSync_FetchChunksServer = grpc.ServerStreamingServer[ChunkPayload]
+
+func _DeltaSync_ListVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeltaSyncServer).ListVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeltaSync_ListVersions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeltaSyncServer).ListVersions(ctx, req.(*FileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeltaSync_GetUploadSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeltaSyncServer).GetUploadSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeltaSync_GetUploadSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeltaSyncServer).GetUploadSession(ctx, req.(*UploadSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeltaSync_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DeltaSyncServer).WatchEvents(m, &grpc.GenericServerStream[WatchEventsRequest, SyncEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DeltaSync_WatchEventsServer = grpc.ServerStreamingServer[SyncEvent]
+
 // DeltaSync_ServiceDesc is the grpc.ServiceDesc for DeltaSync service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -183,6 +479,30 @@ var DeltaSync_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetMissingChunks",
 			Handler:    _DeltaSync_GetMissingChunks_Handler,
 		},
+		{
+			MethodName: "CommitFile",
+			Handler:    _DeltaSync_CommitFile_Handler,
+		},
+		{
+			MethodName: "DeleteFile",
+			Handler:    _DeltaSync_DeleteFile_Handler,
+		},
+		{
+			MethodName: "ListFiles",
+			Handler:    _DeltaSync_ListFiles_Handler,
+		},
+		{
+			MethodName: "GetRecipe",
+			Handler:    _DeltaSync_GetRecipe_Handler,
+		},
+		{
+			MethodName: "ListVersions",
+			Handler:    _DeltaSync_ListVersions_Handler,
+		},
+		{
+			MethodName: "GetUploadSession",
+			Handler:    _DeltaSync_GetUploadSession_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -195,6 +515,16 @@ var DeltaSync_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _DeltaSync_DownloadFile_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "FetchChunks",
+			Handler:       _DeltaSync_FetchChunks_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _DeltaSync_WatchEvents_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "api/proto/sync.proto",
 }
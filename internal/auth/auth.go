@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ErrInvalidToken is returned for any credential that does not identify a user
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Authenticator maps bearer tokens to user names. Tokens are either static API keys or
+// HS256-signed JWTs whose "sub" claim names the user; both are validated locally.
+type Authenticator struct {
+	apiKeys   map[[32]byte]string // sha256(key) -> user, so lookups never compare raw keys
+	jwtSecret []byte
+}
+
+// FromEnv reads DELTASYNC_API_KEYS ("alice:key1,bob:key2") and DELTASYNC_JWT_SECRET.
+// It returns nil when neither is set, which leaves the service open as before.
+func FromEnv() (*Authenticator, error) {
+	keys := os.Getenv("DELTASYNC_API_KEYS")
+	secret := os.Getenv("DELTASYNC_JWT_SECRET")
+	if keys == "" && secret == "" {
+		return nil, nil
+	}
+
+	a := &Authenticator{apiKeys: make(map[[32]byte]string), jwtSecret: []byte(secret)}
+	for _, entry := range strings.Split(keys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		user, key, ok := strings.Cut(entry, ":")
+		if !ok || user == "" || key == "" {
+			return nil, fmt.Errorf("DELTASYNC_API_KEYS entry %q is not user:key", entry)
+		}
+		a.apiKeys[sha256.Sum256([]byte(key))] = user
+	}
+	return a, nil
+}
+
+// Authenticate returns the user a bearer token belongs to
+func (a *Authenticator) Authenticate(token string) (string, error) {
+	if token == "" {
+		return "", ErrInvalidToken
+	}
+	if user, ok := a.apiKeys[sha256.Sum256([]byte(token))]; ok {
+		return user, nil
+	}
+	if len(a.jwtSecret) > 0 && strings.Count(token, ".") == 2 {
+		return a.verifyJWT(token)
+	}
+	return "", ErrInvalidToken
+}
+
+// verifyJWT checks an HS256 signature and the exp/nbf claims
+func (a *Authenticator) verifyJWT(token string) (string, error) {
+	parts := strings.Split(token, ".")
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return "", ErrInvalidToken
+	}
+
+	mac := hmac.New(sha256.New, a.jwtSecret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(sig, mac.Sum(nil)) {
+		return "", ErrInvalidToken
+	}
+
+	var claims struct {
+		Sub string   `json:"sub"`
+		Exp *float64 `json:"exp"`
+		Nbf *float64 `json:"nbf"`
+	}
+	if err := decodeSegment(parts[1], &claims); err != nil || claims.Sub == "" {
+		return "", ErrInvalidToken
+	}
+	now := float64(time.Now().Unix())
+	if claims.Exp != nil && now >= *claims.Exp {
+		return "", ErrInvalidToken
+	}
+	if claims.Nbf != nil && now < *claims.Nbf {
+		return "", ErrInvalidToken
+	}
+	return claims.Sub, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// BearerToken strips the "Bearer " prefix from an Authorization value
+func BearerToken(header string) string {
+	if len(header) > 7 && strings.EqualFold(header[:7], "bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
+
+type userKey struct{}
+
+// WithUser attaches the authenticated user to a context
+func WithUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// User returns the authenticated user, or "" when authentication is disabled
+func User(ctx context.Context) string {
+	user, _ := ctx.Value(userKey{}).(string)
+	return user
+}
+
+// identify resolves the caller from the "authorization" gRPC metadata
+func (a *Authenticator) identify(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var token string
+	if values := md.Get("authorization"); len(values) > 0 {
+		token = BearerToken(values[0])
+	}
+
+	user, err := a.Authenticate(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return WithUser(ctx, user), nil
+}
+
+// UnaryInterceptor rejects unauthenticated unary calls and tags the context with the user
+func (a *Authenticator) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := a.identify(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor does the same for streaming calls
+func (a *Authenticator) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.identify(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &identifiedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// identifiedStream swaps in the context carrying the user
+type identifiedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identifiedStream) Context() context.Context {
+	return s.ctx
+}
+
+// TokenCredentials sends a bearer token with every RPC
+type TokenCredentials struct {
+	Token string
+	// AllowInsecure permits sending the token over plaintext connections, e.g. in-container calls
+	AllowInsecure bool
+}
+
+func (t TokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.Token}, nil
+}
+
+func (t TokenCredentials) RequireTransportSecurity() bool {
+	return !t.AllowInsecure
+}
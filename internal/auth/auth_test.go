@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testSecret = "jwt-secret"
+
+func newTestAuthenticator(t *testing.T) *Authenticator {
+	t.Helper()
+	t.Setenv("DELTASYNC_API_KEYS", "alice:alice-key, bob:bob-key")
+	t.Setenv("DELTASYNC_JWT_SECRET", testSecret)
+	a, err := FromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+// signJWT builds a token from header and claims, signed with HMAC-SHA256 under secret
+func signJWT(t *testing.T, header, claims map[string]any, secret string) string {
+	t.Helper()
+	segment := func(v map[string]any) string {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(raw)
+	}
+	unsigned := segment(header) + "." + segment(claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestAuthenticate(t *testing.T) {
+	a := newTestAuthenticator(t)
+	hs256 := map[string]any{"alg": "HS256", "typ": "JWT"}
+	now := time.Now().Unix()
+
+	valid := signJWT(t, hs256, map[string]any{"sub": "carol", "exp": now + 60}, testSecret)
+	parts := strings.Split(valid, ".")
+	forged := strings.Split(signJWT(t, hs256, map[string]any{"sub": "admin", "exp": now + 60}, testSecret), ".")[1]
+	none := strings.Split(signJWT(t, map[string]any{"alg": "none"}, map[string]any{"sub": "carol"}, testSecret), ".")
+
+	tests := []struct {
+		name  string
+		token string
+		user  string // empty when the token must be refused
+	}{
+		{"api key", "alice-key", "alice"},
+		{"second api key", "bob-key", "bob"},
+		{"unknown api key", "mallory-key", ""},
+		{"empty token", "", ""},
+		{"valid jwt", valid, "carol"},
+		{"jwt without exp or nbf", signJWT(t, hs256, map[string]any{"sub": "carol"}, testSecret), "carol"},
+		{"alg none", none[0] + "." + none[1] + ".", ""},
+		{"alg none with a signature", signJWT(t, map[string]any{"alg": "none"}, map[string]any{"sub": "carol"}, testSecret), ""},
+		{"alg HS512", signJWT(t, map[string]any{"alg": "HS512"}, map[string]any{"sub": "carol"}, testSecret), ""},
+		{"alg RS256", signJWT(t, map[string]any{"alg": "RS256"}, map[string]any{"sub": "carol"}, testSecret), ""},
+		{"wrong secret", signJWT(t, hs256, map[string]any{"sub": "carol"}, "other-secret"), ""},
+		{"swapped claims", parts[0] + "." + forged + "." + parts[2], ""},
+		{"garbled signature", parts[0] + "." + parts[1] + ".!!!!", ""},
+		{"expired", signJWT(t, hs256, map[string]any{"sub": "carol", "exp": now - 1}, testSecret), ""},
+		{"expires now", signJWT(t, hs256, map[string]any{"sub": "carol", "exp": now}, testSecret), ""},
+		{"not yet valid", signJWT(t, hs256, map[string]any{"sub": "carol", "nbf": now + 60}, testSecret), ""},
+		{"already valid", signJWT(t, hs256, map[string]any{"sub": "carol", "nbf": now - 60}, testSecret), "carol"},
+		{"missing sub", signJWT(t, hs256, map[string]any{"exp": now + 60}, testSecret), ""},
+		{"empty sub", signJWT(t, hs256, map[string]any{"sub": ""}, testSecret), ""},
+		{"not a jwt", "a.b", ""},
+		{"bad base64 header", "!!." + parts[1] + "." + parts[2], ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := a.Authenticate(tt.token)
+			if tt.user == "" {
+				if err == nil {
+					t.Fatalf("accepted as %q", user)
+				}
+				return
+			}
+			if err != nil || user != tt.user {
+				t.Fatalf("Authenticate = %q, %v; want %q", user, err, tt.user)
+			}
+		})
+	}
+}
+
+func TestJWTRefusedWithoutSecret(t *testing.T) {
+	t.Setenv("DELTASYNC_API_KEYS", "alice:alice-key")
+	t.Setenv("DELTASYNC_JWT_SECRET", "")
+	a, err := FromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Signed with the empty secret, which must not be accepted as a configured one
+	token := signJWT(t, map[string]any{"alg": "HS256"}, map[string]any{"sub": "carol"}, "")
+	if user, err := a.Authenticate(token); err == nil {
+		t.Fatalf("accepted as %q with no JWT secret configured", user)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("DELTASYNC_API_KEYS", "")
+	t.Setenv("DELTASYNC_JWT_SECRET", "")
+	if a, err := FromEnv(); a != nil || err != nil {
+		t.Fatalf("FromEnv with nothing set = %v, %v; want auth disabled", a, err)
+	}
+
+	for _, keys := range []string{"alice", "alice:", ":key", "alice:key,bob"} {
+		t.Setenv("DELTASYNC_API_KEYS", keys)
+		if _, err := FromEnv(); err == nil {
+			t.Errorf("DELTASYNC_API_KEYS=%q accepted", keys)
+		}
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := map[string]string{
+		"Bearer abc":   "abc",
+		"bearer abc ":  "abc",
+		"BEARER abc":   "abc",
+		"Basic abc":    "",
+		"Bearer":       "",
+		"":             "",
+		"Bearerabcdef": "",
+	}
+	for header, want := range tests {
+		if got := BearerToken(header); got != want {
+			t.Errorf("BearerToken(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+// incoming returns a server-side context carrying the given authorization metadata
+func incoming(authorization string) context.Context {
+	ctx := context.Background()
+	if authorization == "" {
+		return ctx
+	}
+	return metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", authorization))
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	a := newTestAuthenticator(t)
+	intercept := a.UnaryInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/sync.DeltaSync/ListFiles"}
+
+	var seen string
+	handler := func(ctx context.Context, req any) (any, error) {
+		seen = User(ctx)
+		return "ok", nil
+	}
+	if _, err := intercept(incoming("Bearer alice-key"), nil, info, handler); err != nil {
+		t.Fatal(err)
+	}
+	if seen != "alice" {
+		t.Fatalf("handler saw user %q, want alice", seen)
+	}
+
+	for _, authorization := range []string{"", "Bearer wrong-key", "alice-key"} {
+		seen = "unset"
+		_, err := intercept(incoming(authorization), nil, info, handler)
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("authorization %q: got %v, want Unauthenticated", authorization, err)
+		}
+		if seen != "unset" {
+			t.Errorf("authorization %q: handler ran", authorization)
+		}
+	}
+}
+
+// fakeStream is the part of a server stream the interceptor touches
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeStream) Context() context.Context { return s.ctx }
+
+func TestStreamInterceptor(t *testing.T) {
+	a := newTestAuthenticator(t)
+	intercept := a.StreamInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/sync.DeltaSync/UploadChunks"}
+	token := signJWT(t, map[string]any{"alg": "HS256"}, map[string]any{"sub": "carol"}, testSecret)
+
+	var seen string
+	handler := func(srv any, ss grpc.ServerStream) error {
+		seen = User(ss.Context())
+		return nil
+	}
+	if err := intercept(nil, &fakeStream{ctx: incoming("Bearer " + token)}, info, handler); err != nil {
+		t.Fatal(err)
+	}
+	if seen != "carol" {
+		t.Fatalf("handler saw user %q, want carol", seen)
+	}
+
+	seen = "unset"
+	err := intercept(nil, &fakeStream{ctx: incoming("")}, info, handler)
+	if status.Code(err) != codes.Unauthenticated || seen != "unset" {
+		t.Fatalf("unauthenticated stream: err %v, handler saw %q", err, seen)
+	}
+}
@@ -3,8 +3,8 @@ package chunker
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"io"
-	"os"
 
 	"github.com/jotfs/fastcdc-go" // Ensure you run: go get github.com/jotfs/fastcdc-go
 )
@@ -14,7 +14,7 @@ type Chunk struct {
 	Hash   string
 	Size   int
 	Offset int64
-	Data   []byte // Stores raw bytes for reconstruction and gRPC transport
+	Data   []byte // Raw bytes; inside a Scan callback only valid until the callback returns
 }
 
 // Updated constants to align with high-performance CDC standards
@@ -24,53 +24,201 @@ const (
 	MaxChunkSize = 1024 * 256 // 256KB maximum
 )
 
-// AnalyzeFileVSC performs Content-Defined Chunking using the FastCDC algorithm
-func AnalyzeFileVSC(path string) ([]Chunk, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, err
+// MaxChunkLimit is the largest MaxSize allowed, so that a chunk, even sealed and framed, stays
+// well inside gRPC's default 4MB message limit
+const MaxChunkLimit = 1024 * 1024 * 2
+
+// Params bound the chunk sizes content-defined chunking produces. Changing them only affects
+// where new boundaries fall; chunks stay content-addressed, so files chunked with different
+// parameters still sync, they just dedup less against each other.
+type Params struct {
+	MinSize int
+	AvgSize int
+	MaxSize int
+}
+
+// DefaultParams are the sizes Scan and Rechunk use
+var DefaultParams = Params{MinSize: MinChunkSize, AvgSize: AvgChunkSize, MaxSize: MaxChunkSize}
+
+// Validate checks that the sizes are ordered and within what the chunker and the wire accept
+func (p Params) Validate() error {
+	switch {
+	case p.MinSize < 64:
+		return fmt.Errorf("minimum chunk size %d is below 64 bytes", p.MinSize)
+	case p.AvgSize < 256:
+		return fmt.Errorf("average chunk size %d is below 256 bytes", p.AvgSize)
+	case p.MinSize > p.AvgSize || p.AvgSize > p.MaxSize:
+		return fmt.Errorf("chunk sizes must satisfy min <= avg <= max, got %d/%d/%d", p.MinSize, p.AvgSize, p.MaxSize)
+	case p.MaxSize > MaxChunkLimit:
+		return fmt.Errorf("maximum chunk size %d exceeds the %d byte limit", p.MaxSize, MaxChunkLimit)
 	}
-	defer file.Close()
+	return nil
+}
 
+// Scan performs Content-Defined Chunking with DefaultParams; see Params.Scan
+func Scan(r io.Reader, fn func(c Chunk) error) error {
+	return DefaultParams.Scan(r, fn)
+}
+
+// Rechunk re-splits a file with DefaultParams; see Params.Rechunk
+func Rechunk(r io.ReaderAt, size int64, prior []Chunk, fn func(c Chunk) error) (int, error) {
+	return DefaultParams.Rechunk(r, size, prior, fn)
+}
+
+// Scan performs Content-Defined Chunking using the FastCDC algorithm, calling fn for every
+// chunk in file order. Memory stays bounded by the chunker's buffer regardless of input size:
+// c.Data aliases that buffer and is overwritten once fn returns, so copy it if it must be kept.
+func (p Params) Scan(r io.Reader, fn func(c Chunk) error) error {
 	// 1. Configure the FastCDC options for content-based splitting
 	opts := fastcdc.Options{
-		MinSize:     MinChunkSize,
-		AverageSize: AvgChunkSize,
-		MaxSize:     MaxChunkSize,
+		MinSize:     p.MinSize,
+		AverageSize: p.AvgSize,
+		MaxSize:     p.MaxSize,
 	}
 
-	// 2. Initialize the Chunker with the file reader
-	cdcChunker, err := fastcdc.NewChunker(file, opts)
+	// 2. Initialize the Chunker with the reader
+	cdcChunker, err := fastcdc.NewChunker(r, opts)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var chunks []Chunk
 	var offset int64
-
 	for {
 		// 3. Next() finds the next boundary based on content fingerprints
 		cdcData, err := cdcChunker.Next()
 		if err == io.EOF {
-			break
+			return nil
 		}
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		// 4. Fingerprinting: SHA-256 remains the standard for block identification
 		hash := sha256.Sum256(cdcData.Data)
 
-		chunks = append(chunks, Chunk{
+		err = fn(Chunk{
 			Hash:   hex.EncodeToString(hash[:]),
 			Size:   len(cdcData.Data),
 			Offset: offset,
-			Data:   cdcData.Data, // Bytes are preserved for gRPC transport
+			Data:   cdcData.Data,
 		})
+		if err != nil {
+			return err
+		}
 
 		// Update offset based on the actual size of the content-defined chunk
 		offset += int64(len(cdcData.Data))
 	}
+}
+
+// Rechunk splits a file that was previously split into prior, reusing the old boundaries wherever
+// the content is unchanged. Leading chunks are kept while they still hash the same, trailing chunks
+// likewise counting back from the new end of file, and content-defined chunking only runs over the
+// edited region in between. fn sees every chunk in file order exactly as with Scan. It returns how
+// many prior chunks were reused.
+//
+// The last prior chunk was cut by the end of file rather than by its content, so it is never
+// kept as part of the head: after an append its boundary is not one a fresh scan would make.
+// Neither is a chunk below MinSize anywhere but at the end of file.
+func (p Params) Rechunk(r io.ReaderAt, size int64, prior []Chunk, fn func(c Chunk) error) (int, error) {
+	buf := make([]byte, p.MaxSize)
+	// matches reads the bytes chunk c would cover at offset and reports whether they still hash the same
+	matches := func(c Chunk, offset int64) ([]byte, bool, error) {
+		if c.Size <= 0 || offset < 0 || offset+int64(c.Size) > size {
+			return nil, false, nil
+		}
+		if c.Size > len(buf) {
+			buf = make([]byte, c.Size)
+		}
+		data := buf[:c.Size]
+		if _, err := r.ReadAt(data, offset); err != nil {
+			return nil, false, err
+		}
+		hash := sha256.Sum256(data)
+		return data, hex.EncodeToString(hash[:]) == c.Hash, nil
+	}
+
+	// 1. The unchanged head keeps its boundaries
+	var head int64
+	reused := 0
+	for i, c := range prior {
+		if i == len(prior)-1 || c.Size < p.MinSize {
+			break
+		}
+		data, ok, err := matches(c, head)
+		if err != nil {
+			return reused, err
+		}
+		if !ok {
+			break
+		}
+		if err := fn(Chunk{Hash: c.Hash, Size: c.Size, Offset: head, Data: data}); err != nil {
+			return reused, err
+		}
+		head += int64(c.Size)
+		reused++
+	}
+
+	// 2. So does the unchanged tail, as long as it does not overlap the head
+	rest := prior[reused:]
+	tail := size
+	var tailChunks []Chunk
+	for i := len(rest) - 1; i >= 0; i-- {
+		c := rest[i]
+		offset := tail - int64(c.Size)
+		if offset < head || (c.Size < p.MinSize && i != len(rest)-1) {
+			break
+		}
+		_, ok, err := matches(c, offset)
+		if err != nil {
+			return reused, err
+		}
+		if !ok {
+			break
+		}
+		tail = offset
+		tailChunks = append(tailChunks, Chunk{Hash: c.Hash, Size: c.Size, Offset: offset})
+	}
 
-	return chunks, nil
-}
\ No newline at end of file
+	// 3. Only the edited region in between is chunked afresh
+	err := p.Scan(io.NewSectionReader(r, head, tail-head), func(c Chunk) error {
+		c.Offset += head
+		return fn(c)
+	})
+	if err != nil {
+		return reused, err
+	}
+
+	// 4. The tail was found back to front; hand it over in file order
+	for i := len(tailChunks) - 1; i >= 0; i-- {
+		c := tailChunks[i]
+		data, ok, err := matches(c, c.Offset)
+		if err != nil {
+			return reused, err
+		}
+		if !ok {
+			return reused, fmt.Errorf("chunk at offset %d changed while rechunking", c.Offset)
+		}
+		c.Data = data
+		if err := fn(c); err != nil {
+			return reused, err
+		}
+		reused++
+	}
+	return reused, nil
+}
+
+// ReadChunk re-reads a single chunk by its offset and size and checks it still has the same hash,
+// so only the chunks that actually need uploading are ever loaded into memory
+func ReadChunk(r io.ReaderAt, c Chunk) ([]byte, error) {
+	data := make([]byte, c.Size)
+	if _, err := r.ReadAt(data, c.Offset); err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(data)
+	if hex.EncodeToString(hash[:]) != c.Hash {
+		return nil, fmt.Errorf("chunk at offset %d changed since it was scanned", c.Offset)
+	}
+	return data, nil
+}
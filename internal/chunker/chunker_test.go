@@ -0,0 +1,103 @@
+package chunker
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// scanAll chunks data from scratch, dropping the chunk bytes
+func scanAll(t *testing.T, data []byte) []Chunk {
+	t.Helper()
+	var chunks []Chunk
+	err := Scan(bytes.NewReader(data), func(c Chunk) error {
+		c.Data = nil
+		chunks = append(chunks, c)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	return chunks
+}
+
+// rechunkAll rechunks data against prior and returns the new chunks and how many were reused
+func rechunkAll(t *testing.T, data []byte, prior []Chunk) ([]Chunk, int) {
+	t.Helper()
+	var chunks []Chunk
+	reused, err := Rechunk(bytes.NewReader(data), int64(len(data)), prior, func(c Chunk) error {
+		c.Data = nil
+		chunks = append(chunks, c)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Rechunk: %v", err)
+	}
+	return chunks, reused
+}
+
+func sameChunks(a, b []Chunk) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Hash != b[i].Hash || a[i].Offset != b[i].Offset || a[i].Size != b[i].Size {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRechunkAppendsMatchFreshScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	data := make([]byte, 300*1024)
+	rng.Read(data)
+	chunks := scanAll(t, data)
+
+	for i := 0; i < 50; i++ {
+		appended := make([]byte, 2*1024)
+		rng.Read(appended)
+		data = append(data, appended...)
+		chunks, _ = rechunkAll(t, data, chunks)
+	}
+
+	fresh := scanAll(t, data)
+	if !sameChunks(chunks, fresh) {
+		t.Fatalf("after 50 appends Rechunk produced %d chunks, a fresh scan %d", len(chunks), len(fresh))
+	}
+	for _, c := range chunks[:len(chunks)-1] {
+		if c.Size < MinChunkSize {
+			t.Errorf("chunk at offset %d is %d bytes, below the %d byte minimum", c.Offset, c.Size, MinChunkSize)
+		}
+	}
+}
+
+func TestRechunkUnchangedReusesEverything(t *testing.T) {
+	data := make([]byte, 500*1024)
+	rand.New(rand.NewSource(2)).Read(data)
+	prior := scanAll(t, data)
+
+	chunks, reused := rechunkAll(t, data, prior)
+	if !sameChunks(chunks, prior) {
+		t.Fatalf("Rechunk of an unchanged file changed its chunks")
+	}
+	if reused != len(prior) {
+		t.Errorf("reused %d of %d chunks", reused, len(prior))
+	}
+}
+
+func TestRechunkEditInTheMiddle(t *testing.T) {
+	data := make([]byte, 1024*1024)
+	rand.New(rand.NewSource(3)).Read(data)
+	prior := scanAll(t, data)
+
+	edited := append([]byte(nil), data...)
+	copy(edited[512*1024:], "an edit in the middle of the file")
+	chunks, reused := rechunkAll(t, edited, prior)
+	if !sameChunks(chunks, scanAll(t, edited)) {
+		t.Fatalf("Rechunk after an edit differs from a fresh scan")
+	}
+	if reused < len(prior)-2 {
+		t.Errorf("reused only %d of %d chunks around a single edit", reused, len(prior))
+	}
+}
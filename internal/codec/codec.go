@@ -0,0 +1,113 @@
+package codec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"delta-sync/delta-sync-pb/pkg/pb"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Supported lists the codecs this build can encode and decode, in order of preference
+var Supported = []pb.Codec{pb.Codec_CODEC_ZSTD, pb.Codec_CODEC_GZIP, pb.Codec_CODEC_STORED}
+
+// MaxDecodedSize bounds decompressed output so a crafted payload cannot exhaust memory
+const MaxDecodedSize = 16 << 20
+
+var (
+	// EncodeAll/DecodeAll are safe for concurrent use, so one instance serves every stream
+	zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(MaxDecodedSize), zstd.WithDecoderConcurrency(0))
+)
+
+// Parse maps a configuration name (stored, gzip, zstd) to a codec
+func Parse(name string) (pb.Codec, error) {
+	switch name {
+	case "stored", "none":
+		return pb.Codec_CODEC_STORED, nil
+	case "gzip":
+		return pb.Codec_CODEC_GZIP, nil
+	case "zstd":
+		return pb.Codec_CODEC_ZSTD, nil
+	}
+	return pb.Codec_CODEC_STORED, fmt.Errorf("unknown codec %q (want stored, gzip or zstd)", name)
+}
+
+// Negotiate picks the first offered codec this build supports, falling back to stored
+func Negotiate(offered []pb.Codec) pb.Codec {
+	for _, c := range offered {
+		if slices.Contains(Supported, c) {
+			return c
+		}
+	}
+	return pb.Codec_CODEC_STORED
+}
+
+// Encode compresses raw with c. When compression does not make the chunk smaller the raw
+// bytes are returned as stored instead, so the returned codec may differ from c.
+func Encode(c pb.Codec, raw []byte) (pb.Codec, []byte, error) {
+	var out []byte
+	switch c {
+	case pb.Codec_CODEC_STORED:
+		return c, raw, nil
+
+	case pb.Codec_CODEC_ZSTD:
+		out = zstdEncoder.EncodeAll(raw, nil)
+
+	case pb.Codec_CODEC_GZIP:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(raw); err != nil {
+			return c, nil, err
+		}
+		if err := w.Close(); err != nil {
+			return c, nil, err
+		}
+		out = buf.Bytes()
+
+	default:
+		return c, nil, fmt.Errorf("unsupported codec %v", c)
+	}
+
+	if len(out) >= len(raw) {
+		return pb.Codec_CODEC_STORED, raw, nil
+	}
+	return c, out, nil
+}
+
+// Decode reverses Encode
+func Decode(c pb.Codec, data []byte) ([]byte, error) {
+	switch c {
+	case pb.Codec_CODEC_STORED:
+		return data, nil
+
+	case pb.Codec_CODEC_ZSTD:
+		out, err := zstdDecoder.DecodeAll(data, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(out) > MaxDecodedSize {
+			return nil, fmt.Errorf("decoded chunk exceeds %d bytes", MaxDecodedSize)
+		}
+		return out, nil
+
+	case pb.Codec_CODEC_GZIP:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		out, err := io.ReadAll(io.LimitReader(r, MaxDecodedSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(out) > MaxDecodedSize {
+			return nil, fmt.Errorf("decoded chunk exceeds %d bytes", MaxDecodedSize)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unsupported codec %v", c)
+}
@@ -0,0 +1,59 @@
+package dashboard
+
+import (
+	"context"
+	"delta-sync/delta-sync-pb/pkg/pb"
+	"delta-sync/internal/events"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// Source yields one user's sync events until ctx is cancelled, then closes the channel.
+// token is the user's credential, for sources that have to authenticate upstream.
+type Source func(ctx context.Context, owner, token string) (<-chan events.Event, error)
+
+// BusSource reads events straight from an in-process bus, for single-binary deployments
+func BusSource(bus *events.Bus) Source {
+	return func(ctx context.Context, owner, token string) (<-chan events.Event, error) {
+		sub := bus.Subscribe(events.ForOwner(owner))
+		go func() {
+			<-ctx.Done()
+			sub.Close()
+		}()
+		return sub.C, nil
+	}
+}
+
+// GRPCSource follows the WatchEvents stream of a DeltaSync server running in another process
+func GRPCSource(client pb.DeltaSyncClient) Source {
+	return func(ctx context.Context, owner, token string) (<-chan events.Event, error) {
+		if token != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+		}
+		stream, err := client.WatchEvents(ctx, &pb.WatchEventsRequest{})
+		if err != nil {
+			return nil, err
+		}
+
+		ch := make(chan events.Event)
+		go func() {
+			defer close(ch)
+			for {
+				in, err := stream.Recv()
+				if err != nil {
+					return
+				}
+				e := events.FromProto(owner, in)
+				if e == nil {
+					continue
+				}
+				select {
+				case ch <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return ch, nil
+	}
+}
@@ -0,0 +1,96 @@
+package dashboard
+
+import (
+	"delta-sync/internal/events"
+	"fmt"
+	"html"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// upload is the latest state of one file's upload as shown on the dashboard
+type upload struct {
+	label   string // SYNCING, SYNCED or FAILED
+	detail  string
+	percent float64
+}
+
+// Progress turns one user's event stream into the #sync-progress panel, one bar per file
+type Progress struct {
+	uploads map[string]upload
+}
+
+func NewProgress() *Progress {
+	return &Progress{uploads: make(map[string]upload)}
+}
+
+// Apply records an event and renders all running uploads as an out-of-band HTMX swap.
+// Finished uploads are shown one last time and then forgotten.
+func (p *Progress) Apply(e events.Event) string {
+	file := e.Info().File
+
+	switch e := e.(type) {
+	case events.SyncStarted:
+		p.uploads[file] = upload{label: "SYNCING", detail: fmt.Sprintf("0 B / %s", FormatBytes(e.Bytes))}
+	case events.ChunkStored:
+		p.uploads[file] = upload{
+			label: "SYNCING",
+			detail: fmt.Sprintf("%s / %s • %s/s • ETA %s",
+				FormatBytes(e.BytesDone), FormatBytes(e.BytesTotal), FormatBytes(int64(e.BytesPerSecond)), e.ETA.Round(time.Second)),
+			percent: e.Percent(),
+		}
+	case events.SyncCompleted:
+		p.uploads[file] = upload{
+			label:   "SYNCED",
+			detail:  fmt.Sprintf("%d chunks • %s in %s", e.Chunks, FormatBytes(e.Bytes), e.Duration.Round(time.Millisecond)),
+			percent: 100,
+		}
+	case events.SyncFailed:
+		last := p.uploads[file]
+		p.uploads[file] = upload{label: "FAILED", detail: e.Err, percent: last.percent}
+	}
+
+	names := make([]string, 0, len(p.uploads))
+	for name := range p.uploads {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := `
+            <div id="sync-progress" hx-swap-oob="true" class="mb-10 empty:hidden space-y-3">`
+	for _, name := range names {
+		u := p.uploads[name]
+		if u.label != "SYNCING" {
+			delete(p.uploads, name)
+		}
+
+		out += fmt.Sprintf(`
+                <div class="p-4 bg-blue-900 border border-blue-700 rounded-md">
+                    <div class="flex items-baseline justify-between mb-1">
+                        <p class="text-xs font-bold text-blue-300">%s: %s</p>
+                        <p class="text-[10px] mono text-blue-300/70">%s</p>
+                    </div>
+                    <div class="w-full bg-gray-700 rounded-full h-2">
+                        <div class="bg-blue-500 h-2 rounded-full transition-all" style="width: %.1f%%"></div>
+                    </div>
+                </div>`, u.label, html.EscapeString(filepath.Base(name)), html.EscapeString(u.detail), u.percent)
+	}
+	out += `
+            </div>`
+	return out
+}
+
+// FormatBytes renders a byte count for humans
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
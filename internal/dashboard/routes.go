@@ -0,0 +1,275 @@
+package dashboard
+
+import (
+	"context"
+	"delta-sync/delta-sync-pb/pkg/pb"
+	"delta-sync/internal/auth"
+	"delta-sync/internal/db"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc/metadata"
+)
+
+// tokenCookie holds the dashboard user's API key or JWT after signing in
+const tokenCookie = "deltasync_token"
+
+// Config is what the dashboard needs from the binary hosting it
+type Config struct {
+	RemoteDB *db.RemoteDB
+	Client   pb.DeltaSyncClient  // serves /download
+	Events   Source              // live upload progress for /ws
+	Auth     *auth.Authenticator // nil leaves the dashboard open
+	// AllowedOrigins lists extra origins whose pages may open /ws; the dashboard's own host always can
+	AllowedOrigins []string
+}
+
+// Register mounts the dashboard pages and its HTMX API on e
+func Register(e *echo.Echo, cfg Config) {
+	remoteDB, client, authenticator := cfg.RemoteDB, cfg.Client, cfg.Auth
+
+	hub := NewHub(cfg.AllowedOrigins)
+	go hub.Run()
+
+	// 1. Serve the static HTML file
+	e.GET("/", func(c echo.Context) error {
+		// Ensure this path matches your Dockerfile COPY instruction
+		return c.File("web/index.html")
+	})
+
+	// Sign-in stores the token in a cookie and reloads the page so every panel picks it up
+	e.POST("/login", func(c echo.Context) error {
+		if authenticator == nil {
+			return c.NoContent(http.StatusNoContent)
+		}
+		token := strings.TrimSpace(c.FormValue("token"))
+		if _, err := authenticator.Authenticate(token); err != nil {
+			return c.HTML(http.StatusOK, loginForm("Invalid or expired token"))
+		}
+		c.SetCookie(&http.Cookie{
+			Name:     tokenCookie,
+			Value:    token,
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   c.Scheme() == "https",
+			SameSite: http.SameSiteLaxMode,
+		})
+		c.Response().Header().Set("HX-Refresh", "true")
+		return c.NoContent(http.StatusOK)
+	})
+
+	// Everything below is scoped to the signed-in user
+	user := e.Group("", requireUser(authenticator))
+
+	// 2. API for HTMX injection
+	user.GET("/api/files", func(c echo.Context) error {
+		files, err := remoteDB.GetAllRecipes(owner(c))
+		if err != nil {
+			return c.String(http.StatusInternalServerError, "Failed to load registry")
+		}
+
+		out := ""
+		for i, f := range files {
+			displayName := filepath.Base(f.Name)
+			timeLabel := f.UpdatedAt.Format("Jan 02, 15:04")
+			fileParam := url.QueryEscape(f.Name)
+
+			out += fmt.Sprintf(`
+            <div class="group flex items-center justify-between p-6 rounded-2xl bg-white/[0.02] border border-white/5 hover:border-green-500/40 hover:bg-green-500/[0.03] transition-all duration-500">
+                <div class="flex items-center gap-5">
+                    <div class="w-12 h-12 rounded-xl bg-slate-800/50 flex items-center justify-center group-hover:bg-green-500/10 transition-all border border-white/5 group-hover:border-green-500/20">
+                        <svg class="w-6 h-6 text-slate-500 group-hover:text-green-400" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+                            <path stroke-linecap="round" stroke-linejoin="round" stroke-width="1.5" d="M7 21h10a2 2 0 002-2V9.414a1 1 0 00-.293-.707l-5.414-5.414A1 1 0 0012.586 3H7a2 2 0 00-2 2v14a2 2 0 002 2z"></path>
+                        </svg>
+                    </div>
+                    <div class="overflow-hidden">
+                        <p class="text-sm font-bold text-slate-200 truncate max-w-[200px] sm:max-w-md tracking-tight">%s</p>
+                        <div class="flex items-center gap-2 mt-0.5">
+                            <p class="text-[10px] text-slate-500 uppercase tracking-[0.1em] font-medium">Verified Block</p>
+                            <span class="text-slate-700">•</span>
+                            <p class="text-[10px] text-green-500/60 mono font-bold uppercase tracking-tighter">Synced: %s</p>
+                        </div>
+                    </div>
+                </div>
+                <div class="flex items-center gap-4">
+                    <button hx-get="/api/versions?file=%s" hx-target="#versions-%d" class="opacity-0 translate-x-4 group-hover:opacity-100 group-hover:translate-x-0 transition-all duration-300 border border-white/10 text-slate-300 text-[10px] font-black px-5 py-2.5 rounded-xl hover:border-green-500/40 hover:text-green-400 active:scale-90">
+                        HISTORY
+                    </button>
+                    <a href="/download?file=%s" class="opacity-0 translate-x-4 group-hover:opacity-100 group-hover:translate-x-0 transition-all duration-300 bg-green-500 text-slate-950 text-[10px] font-black px-5 py-2.5 rounded-xl hover:bg-green-400 active:scale-90 shadow-[0_0_15px_rgba(74,222,128,0.2)]">
+                        RECONSTRUCT
+                    </a>
+                </div>
+            </div>
+            <div id="versions-%d" class="empty:hidden -mt-2"></div>`, html.EscapeString(displayName), timeLabel, fileParam, i, fileParam, i)
+		}
+
+		if out == "" {
+			out = `<div class="text-center py-20 text-slate-600 text-xs tracking-widest uppercase italic">Registry Empty.</div>`
+		}
+
+		return c.HTML(http.StatusOK, out)
+	})
+
+	// Version history panel for one file, injected below its registry entry
+	user.GET("/api/versions", func(c echo.Context) error {
+		return renderVersions(c, remoteDB, c.QueryParam("file"))
+	})
+
+	// Restore makes an old version current again and re-renders the history panel
+	user.POST("/api/restore", func(c echo.Context) error {
+		fileName := c.QueryParam("file")
+		version, err := strconv.Atoi(c.QueryParam("version"))
+		if err != nil {
+			return c.String(http.StatusBadRequest, "Invalid version")
+		}
+		if _, err := remoteDB.RestoreVersion(owner(c), fileName, version); err != nil {
+			return c.String(http.StatusInternalServerError, "Failed to restore version")
+		}
+		return renderVersions(c, remoteDB, fileName)
+	})
+
+	// 3. Download Route: Bridges HTTP to gRPC internally
+	user.GET("/download", func(c echo.Context) error {
+		fileName := c.QueryParam("file")
+		version, _ := strconv.Atoi(c.QueryParam("version")) // empty means the current version
+
+		// Forward the user's own token so the gRPC server applies the same namespace
+		ctx := context.Background()
+		if token, ok := c.Get("token").(string); ok && token != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+		}
+		stream, err := client.DownloadFile(ctx, &pb.FileRequest{FileName: fileName, Version: int32(version)})
+		if err != nil {
+			return c.String(http.StatusNotFound, "File recipe not found")
+		}
+
+		downloadName := filepath.Base(fileName)
+		if version > 0 {
+			ext := filepath.Ext(downloadName)
+			downloadName = fmt.Sprintf("%s.v%d%s", strings.TrimSuffix(downloadName, ext), version, ext)
+		}
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%s", downloadName))
+		c.Response().Header().Set(echo.HeaderContentType, "application/octet-stream")
+		c.Response().WriteHeader(http.StatusOK)
+
+		for {
+			chunk, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			c.Response().Write(chunk.Data)
+		}
+		return nil
+	})
+
+	// WebSocket endpoint
+	user.GET("/ws", func(c echo.Context) error {
+		token, _ := c.Get("token").(string)
+		return hub.ServeEvents(c, cfg.Events, owner(c), token)
+	})
+}
+
+// requireUser resolves the caller from a bearer header or the HttpOnly sign-in cookie. Tokens
+// are never taken from the query string, where they would end up in logs and browser history.
+// With authentication disabled every request acts as the default (empty) owner.
+func requireUser(authenticator *auth.Authenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if authenticator == nil {
+				return next(c)
+			}
+
+			token := auth.BearerToken(c.Request().Header.Get(echo.HeaderAuthorization))
+			if token == "" {
+				if cookie, err := c.Cookie(tokenCookie); err == nil {
+					token = cookie.Value
+				}
+			}
+
+			name, err := authenticator.Authenticate(token)
+			if err != nil {
+				// HTMX panels get a sign-in form swapped in; anything else a plain 401
+				if c.Request().Header.Get("HX-Request") == "true" {
+					return c.HTML(http.StatusOK, loginForm(""))
+				}
+				return c.String(http.StatusUnauthorized, "Authentication required")
+			}
+			c.Set("user", name)
+			c.Set("token", token)
+			return next(c)
+		}
+	}
+}
+
+// owner returns the namespace of the current request
+func owner(c echo.Context) string {
+	name, _ := c.Get("user").(string)
+	return name
+}
+
+// loginForm renders the sign-in fragment shown in place of the registry
+func loginForm(message string) string {
+	errorLine := ""
+	if message != "" {
+		errorLine = fmt.Sprintf(`
+                <p class="text-[10px] text-red-400 uppercase tracking-widest">%s</p>`, html.EscapeString(message))
+	}
+	return fmt.Sprintf(`
+            <form hx-post="/login" hx-target="this" hx-swap="outerHTML" class="flex flex-col items-center gap-4 py-20">
+                <p class="text-xs text-slate-500 uppercase tracking-widest">Sign in with your API key or token</p>
+                <input type="password" name="token" autocomplete="off" class="w-full max-w-sm px-4 py-2.5 rounded-xl bg-black/30 border border-white/10 text-slate-200 text-xs mono focus:outline-none focus:border-green-500/40">%s
+                <button type="submit" class="bg-green-500 text-slate-950 text-[10px] font-black px-5 py-2.5 rounded-xl hover:bg-green-400 active:scale-90">SIGN IN</button>
+            </form>`, errorLine)
+}
+
+// renderVersions writes the version history of a file as an HTMX fragment
+func renderVersions(c echo.Context, remoteDB *db.RemoteDB, fileName string) error {
+	versions, err := remoteDB.ListVersions(owner(c), fileName)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to load history")
+	}
+
+	fileParam := url.QueryEscape(fileName)
+	out := `<div class="ml-16 mr-6 mb-2 rounded-2xl border border-white/5 bg-black/20 divide-y divide-white/5">`
+	for i, v := range versions {
+		action := fmt.Sprintf(`
+                <button hx-post="/api/restore?file=%s&version=%d" hx-target="closest div[id^='versions-']" hx-confirm="Restore version %d?" class="text-[10px] font-black px-3 py-1.5 rounded-lg border border-white/10 text-slate-300 hover:border-green-500/40 hover:text-green-400">RESTORE</button>`,
+			fileParam, v.Version, v.Version)
+		if i == 0 {
+			action = `
+                <span class="text-[10px] font-black px-3 py-1.5 text-green-500/60 mono uppercase">Current</span>`
+		}
+
+		out += fmt.Sprintf(`
+            <div class="flex items-center justify-between px-5 py-3">
+                <div class="flex items-center gap-3 text-[10px] mono text-slate-400">
+                    <span class="font-bold text-slate-200">v%d</span>
+                    <span class="text-slate-700">•</span>
+                    <span>%s</span>
+                    <span class="text-slate-700">•</span>
+                    <span>%s</span>
+                    <span class="text-slate-700">•</span>
+                    <span>%d chunks</span>
+                </div>
+                <div class="flex items-center gap-2">
+                    <a href="/download?file=%s&version=%d" class="text-[10px] font-black px-3 py-1.5 rounded-lg bg-green-500/10 text-green-400 hover:bg-green-500/20">DOWNLOAD</a>%s
+                </div>
+            </div>`, v.Version, v.CreatedAt.Format("Jan 02, 15:04"), FormatBytes(v.Size), v.ChunkCount, fileParam, v.Version, action)
+	}
+	if len(versions) == 0 {
+		out += `<div class="px-5 py-3 text-[10px] text-slate-600 uppercase tracking-widest italic">No history recorded.</div>`
+	}
+	out += `</div>`
+
+	return c.HTML(http.StatusOK, out)
+}
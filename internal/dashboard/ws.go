@@ -0,0 +1,200 @@
+package dashboard
+
+import (
+	"context"
+	"delta-sync/internal/events"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// writeWait is how long a single write may take before the client is considered gone
+	writeWait = 10 * time.Second
+	// pongWait is how long the browser may stay silent; pings every pingPeriod keep it talking
+	pongWait   = 60 * time.Second
+	pingPeriod = pongWait * 9 / 10
+	// sendBuffer is how many frames may queue for a client before it is evicted as too slow
+	sendBuffer = 64
+	// maxMessageSize caps what the browser may send; the dashboard never expects anything
+	maxMessageSize = 512
+)
+
+// Hub owns every dashboard websocket. Connections join and leave through the register and
+// unregister channels, so only Run touches the client set, and each connection has exactly one
+// writer goroutine fed by its own buffered queue.
+type Hub struct {
+	register   chan *client
+	unregister chan *client
+	clients    map[*client]bool
+	upgrader   websocket.Upgrader
+	origins    map[string]bool
+}
+
+// client is one browser tab following its user's uploads
+type client struct {
+	hub  *Hub
+	conn *websocket.Conn
+	send chan []byte
+	done chan struct{} // closed by the hub once the client is unregistered
+}
+
+// NewHub accepts websocket connections from the dashboard's own host plus any of allowedOrigins
+// (e.g. "https://sync.example.com"); call Run before serving connections
+func NewHub(allowedOrigins []string) *Hub {
+	h := &Hub{
+		register:   make(chan *client),
+		unregister: make(chan *client),
+		clients:    make(map[*client]bool),
+		origins:    make(map[string]bool, len(allowedOrigins)),
+	}
+	for _, origin := range allowedOrigins {
+		h.origins[strings.ToLower(strings.TrimRight(origin, "/"))] = true
+	}
+	h.upgrader = websocket.Upgrader{CheckOrigin: h.checkOrigin}
+	return h
+}
+
+// OriginsFromEnv reads extra allowed websocket origins from DASHBOARD_ORIGINS (comma separated)
+func OriginsFromEnv() []string {
+	var origins []string
+	for _, origin := range strings.Split(os.Getenv("DASHBOARD_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// checkOrigin rejects cross-site websocket hijacking: browsers always send Origin, and it must
+// be the dashboard itself or explicitly allowed. Non-browser clients send no Origin at all.
+func (h *Hub) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if h.origins[strings.ToLower(origin)] {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// Run manages the client set until the process exits
+func (h *Hub) Run() {
+	for {
+		select {
+		case c := <-h.register:
+			h.clients[c] = true
+		case c := <-h.unregister:
+			if h.clients[c] {
+				delete(h.clients, c)
+				close(c.done)
+			}
+		}
+	}
+}
+
+// ServeEvents upgrades the request and pushes the user's upload progress to the browser.
+// It returns once the connection is closed.
+func (h *Hub) ServeEvents(c echo.Context, source Source, owner, token string) error {
+	conn, err := h.upgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		return nil // the upgrader already replied with an HTTP error
+	}
+
+	cl := &client{hub: h, conn: conn, send: make(chan []byte, sendBuffer), done: make(chan struct{})}
+	h.register <- cl
+
+	// The feed outlives the HTTP request, so it is tied to the client instead
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-cl.done
+		cancel()
+	}()
+
+	feed, err := source(ctx, owner, token)
+	if err != nil {
+		log.Printf("Dashboard cannot follow sync events: %v", err)
+		h.unregister <- cl
+		conn.Close()
+		return nil
+	}
+
+	go cl.readPump()
+	go cl.feedPump(feed)
+	cl.writePump()
+	return nil
+}
+
+// feedPump renders events into the client's queue; a client that lets the queue fill up is
+// evicted rather than allowed to hold up anyone else
+func (c *client) feedPump(feed <-chan events.Event) {
+	defer func() { c.hub.unregister <- c }()
+
+	progress := NewProgress()
+	for e := range feed {
+		select {
+		case c.send <- []byte(progress.Apply(e)):
+		case <-c.done:
+			return
+		default:
+			log.Printf("Evicting slow dashboard client %s", c.conn.RemoteAddr())
+			return
+		}
+	}
+}
+
+// readPump discards anything the browser sends and notices when it goes away or stops
+// answering pings
+func (c *client) readPump() {
+	defer func() { c.hub.unregister <- c }()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		if _, _, err := c.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// writePump is the connection's only writer: queued frames and keepalive pings
+func (c *client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case msg := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				c.hub.unregister <- c
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.hub.unregister <- c
+				return
+			}
+		case <-c.done:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+			return
+		}
+	}
+}
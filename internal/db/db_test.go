@@ -0,0 +1,40 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testDB connects to the database named by DATABASE_URL_DELTASYNC, skipping the test when it is
+// unset. It returns an owner of its own so tests never see each other's files, and removes that
+// owner's rows afterwards.
+func testDB(tb testing.TB) (*RemoteDB, string) {
+	tb.Helper()
+	connStr := os.Getenv("DATABASE_URL_DELTASYNC")
+	if connStr == "" {
+		tb.Skip("DATABASE_URL_DELTASYNC is not set")
+	}
+	pool, err := pgxpool.New(context.Background(), connStr)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(pool.Close)
+	if err := ensureSchema(pool); err != nil {
+		tb.Fatal(err)
+	}
+
+	id := make([]byte, 8)
+	rand.Read(id)
+	owner := "test-" + hex.EncodeToString(id)
+	tb.Cleanup(func() {
+		for _, table := range []string{"file_recipes", "file_versions", "upload_sessions", "chunk_owners"} {
+			pool.Exec(context.Background(), `DELETE FROM `+table+` WHERE owner = $1`, owner)
+		}
+	})
+	return &RemoteDB{Pool: pool}, owner
+}
@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// OrphanChunk is a stored chunk that no recipe or retained version references
+type OrphanChunk struct {
+	Hash string
+	Size int // bytes held by the chunk store, i.e. after compression
+}
+
+// liveHashes expands every hash still reachable from the registry or from an upload that is
+// still in progress. $1 is the number of versions retained per file (0 keeps the whole history).
+const liveHashes = `
+	SELECT unnest(chunk_hashes) AS hash FROM file_recipes
+	UNION
+	SELECT unnest(chunk_hashes) FROM upload_sessions
+	UNION
+	SELECT unnest(chunk_hashes) FROM (
+		SELECT chunk_hashes, row_number() OVER (PARTITION BY owner, file_name ORDER BY version DESC) AS rn
+		FROM file_versions
+	) v WHERE $1 = 0 OR v.rn <= $1`
+
+// FindOrphanChunks returns up to limit unreferenced chunks older than createdBefore,
+// ordered by hash and starting after the given hash so callers can page through them
+func (r *RemoteDB) FindOrphanChunks(keepVersions int, createdBefore time.Time, after string, limit int) ([]OrphanChunk, error) {
+	query := `WITH live AS (` + liveHashes + `)
+			  SELECT c.hash, COALESCE(c.stored_size, c.size) FROM chunks c
+			  LEFT JOIN live l ON l.hash = c.hash
+			  WHERE l.hash IS NULL AND c.created_at < $2 AND c.hash > $3
+			  ORDER BY c.hash
+			  LIMIT $4`
+	rows, err := r.Pool.Query(context.Background(), query, keepVersions, createdBefore, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orphans []OrphanChunk
+	for rows.Next() {
+		var o OrphanChunk
+		if err := rows.Scan(&o.Hash, &o.Size); err != nil {
+			return nil, err
+		}
+		orphans = append(orphans, o)
+	}
+	return orphans, rows.Err()
+}
+
+// DeleteOrphanChunks removes the given chunks and every claim to them. It returns the deleted
+// orphans.
+//
+// Rows a commit is checking are share-locked by CommitFileRecipe, so they are skipped here and
+// left for a later pass. Reachability is re-checked only once the rest are locked: the DELETE's
+// snapshot then includes every commit that finished before the lock, so a chunk referenced again
+// since it was found is kept.
+func (r *RemoteDB) DeleteOrphanChunks(keepVersions int, hashes []string) ([]OrphanChunk, error) {
+	ctx := context.Background()
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `SELECT hash FROM chunks WHERE hash = ANY($1) FOR UPDATE SKIP LOCKED`, hashes)
+	if err != nil {
+		return nil, err
+	}
+	var locked []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		locked = append(locked, hash)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(locked) == 0 {
+		return nil, nil
+	}
+
+	query := `WITH live AS (` + liveHashes + `),
+			  deleted AS (
+				  DELETE FROM chunks c
+				  WHERE c.hash = ANY($2) AND NOT EXISTS (SELECT 1 FROM live l WHERE l.hash = c.hash)
+				  RETURNING c.hash, COALESCE(c.stored_size, c.size) AS size
+			  ),
+			  unclaimed AS (
+				  DELETE FROM chunk_owners o USING deleted d WHERE o.hash = d.hash
+			  )
+			  SELECT hash, size FROM deleted`
+	rows, err = tx.Query(ctx, query, keepVersions, locked)
+	if err != nil {
+		return nil, err
+	}
+	var deleted []OrphanChunk
+	for rows.Next() {
+		var o OrphanChunk
+		if err := rows.Scan(&o.Hash, &o.Size); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		deleted = append(deleted, o)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return deleted, tx.Commit(ctx)
+}
+
+// CountPrunableVersions reports how many history entries fall outside the retention window
+func (r *RemoteDB) CountPrunableVersions(keepVersions int) (int64, error) {
+	if keepVersions <= 0 {
+		return 0, nil
+	}
+	query := `SELECT count(*) FROM (
+				  SELECT row_number() OVER (PARTITION BY owner, file_name ORDER BY version DESC) AS rn
+				  FROM file_versions
+			  ) v WHERE v.rn > $1`
+	var n int64
+	err := r.Pool.QueryRow(context.Background(), query, keepVersions).Scan(&n)
+	return n, err
+}
+
+// PruneVersions deletes every history entry beyond the newest keepVersions per file
+func (r *RemoteDB) PruneVersions(keepVersions int) (int64, error) {
+	if keepVersions <= 0 {
+		return 0, nil
+	}
+	query := `DELETE FROM file_versions f USING (
+				  SELECT owner, file_name, version,
+				  row_number() OVER (PARTITION BY owner, file_name ORDER BY version DESC) AS rn
+				  FROM file_versions
+			  ) v
+			  WHERE f.owner = v.owner AND f.file_name = v.file_name AND f.version = v.version AND v.rn > $1`
+	tag, err := r.Pool.Exec(context.Background(), query, keepVersions)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteChunkBytes calls del to remove a collected chunk's bytes from the chunk store, unless
+// the chunk was uploaded again since its metadata was deleted. The chunk's lock keeps an upload
+// from storing it while the check and the delete run.
+func (r *RemoteDB) DeleteChunkBytes(hash string, del func() error) error {
+	ctx := context.Background()
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, lockChunk, hash); err != nil {
+		return err
+	}
+	var registered bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM chunks WHERE hash = $1)`, hash).Scan(&registered); err != nil {
+		return err
+	}
+	if registered {
+		return nil
+	}
+	if err := del(); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
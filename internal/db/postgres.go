@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool" // Ensure you ran 'go get github.com/jackc/pgx/v5'
 )
 
@@ -33,64 +35,402 @@ func InitPostgres() *RemoteDB {
 		log.Fatal("❌ Connection failed! Could not ping Neon database:", err)
 	}
 
+	// 3. Make sure the tables (including ones added by newer releases) exist
+	if err := ensureSchema(pool); err != nil {
+		log.Fatal("❌ Could not prepare database schema:", err)
+	}
+
 	fmt.Println("🚀 Success! Server is connected to Neon.")
 	return &RemoteDB{Pool: pool}
 }
 
-// GetMissingChunks checks the 'chunks' table for existing fingerprints
-func (db *RemoteDB) GetMissingChunks(hashes []string) ([]string, error) {
-	var missing []string
+// MissingChunksBatchSize caps how many hashes are sent to Neon in a single query, keeping
+// statements well below protocol limits for very large signatures
+const MissingChunksBatchSize = 10000
+
+// GetMissingChunks returns the hashes the owner still has to upload, in signature order: those not
+// fully registered in the 'chunks' table, and those registered but never uploaded by this owner.
+// Answering per owner means the shared table never reveals which chunks other users hold.
+// Each batch is one set-based anti-join instead of one round trip per hash.
+func (db *RemoteDB) GetMissingChunks(owner string, hashes []string) ([]string, error) {
+	// Files often repeat chunks (zero-filled regions, duplicated blocks); ask about each once
+	seen := make(map[string]bool, len(hashes))
+	unique := make([]string, 0, len(hashes))
 	for _, hash := range hashes {
-		var exists bool
-		query := `SELECT EXISTS(SELECT 1 FROM chunks WHERE hash=$1);`
-		err := db.Pool.QueryRow(context.Background(), query, hash).Scan(&exists)
+		if !seen[hash] {
+			seen[hash] = true
+			unique = append(unique, hash)
+		}
+	}
+
+	query := `SELECT h.hash
+			  FROM unnest($1::text[]) WITH ORDINALITY AS h(hash, pos)
+			  WHERE NOT EXISTS (SELECT 1 FROM chunks c JOIN chunk_owners o ON o.hash = c.hash
+								WHERE c.hash = h.hash AND o.owner = $2 AND c.stored_size IS NOT NULL)
+			  ORDER BY h.pos`
+
+	var missing []string
+	for start := 0; start < len(unique); start += MissingChunksBatchSize {
+		batch := unique[start:min(start+MissingChunksBatchSize, len(unique))]
+
+		rows, err := db.Pool.Query(context.Background(), query, batch, owner)
 		if err != nil {
 			return nil, err
 		}
-		if !exists {
+		for rows.Next() {
+			var hash string
+			if err := rows.Scan(&hash); err != nil {
+				rows.Close()
+				return nil, err
+			}
 			missing = append(missing, hash)
 		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
 	}
 	return missing, nil
 }
 
-// RegisterChunk saves the raw bytes directly to Neon
-func (r *RemoteDB) RegisterChunk(hash string, data []byte, size int) error {
-	// Added 'data' column to your previous logic to ensure the file is stored
-	query := `INSERT INTO chunks (hash, data, size) 
-			  VALUES ($1, $2, $3)
-			  ON CONFLICT (hash) DO NOTHING`
-	_, err := r.Pool.Exec(context.Background(), query, hash, data, size)
-	return err
+// lockChunk serializes writers of one chunk's bytes for the rest of the transaction: uploads
+// storing them and garbage collection deleting them
+const lockChunk = `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`
+
+// StoreChunk calls put to write a chunk's bytes to the chunk store, then records its metadata
+// along with the uploading owner's claim to it. size is the uncompressed size; codec and
+// storedSize describe the bytes put writes. Holding the chunk's lock throughout keeps garbage
+// collection from deleting the bytes between the write and the registration. put is handed the
+// transaction so a store inside Postgres writes on its connection rather than taking another.
+//
+// A chunk that is already registered keeps its stored bytes and metadata, and only gains the
+// claim: the codec is chosen per server, so writing this upload's encoding could leave the
+// recorded codec describing bytes it did not produce.
+func (r *RemoteDB) StoreChunk(ctx context.Context, owner, hash string, size int, codec int32, storedSize int, put func(tx pgx.Tx) error) error {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, lockChunk, hash); err != nil {
+		return err
+	}
+	var registered bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM chunks WHERE hash = $1 AND stored_size IS NOT NULL)`, hash).Scan(&registered)
+	if err != nil {
+		return err
+	}
+	if registered {
+		_, err := tx.Exec(ctx, `INSERT INTO chunk_owners (owner, hash) VALUES ($1, $2) ON CONFLICT DO NOTHING`, owner, hash)
+		if err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	}
+	if err := put(tx); err != nil {
+		return err
+	}
+
+	// put replaced whatever an unfinished upload may have left, so this metadata describes the
+	// stored bytes whichever server wrote them before
+	query := `WITH chunk AS (
+				  INSERT INTO chunks (hash, size, codec, stored_size)
+				  VALUES ($1, $2, $3, $4)
+				  ON CONFLICT (hash) DO UPDATE SET size = $2, codec = $3, stored_size = $4
+				  RETURNING hash
+			  )
+			  INSERT INTO chunk_owners (owner, hash) SELECT $5, hash FROM chunk
+			  ON CONFLICT DO NOTHING`
+	if _, err := tx.Exec(ctx, query, hash, size, codec, storedSize, owner); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
-// UpdateFileRecipe uses Postgres native array support for the hash list
-func (r *RemoteDB) UpdateFileRecipe(fileName string, hashes []string) error {
-	query := `INSERT INTO file_recipes (file_name, chunk_hashes) 
-			  VALUES ($1, $2) 
-			  ON CONFLICT (file_name) 
-			  DO UPDATE SET chunk_hashes = $2, updated_at = CURRENT_TIMESTAMP`
-	
+// CommitFileRecipe publishes a new recipe in the owner's namespace and appends it to the file's
+// version history, but only if the owner has uploaded every chunk it references. It returns the
+// version the recipe was stored as, or the hashes still missing (in recipe order) with nothing
+// published. A chunk stored by another user counts as missing until this owner uploads it too.
+func (r *RemoteDB) CommitFileRecipe(owner, fileName string, hashes []string, size int64) (int, []string, error) {
+	ctx := context.Background()
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Share-lock the chunks until the recipe is published so garbage collection cannot delete
+	// them in between; a chunk it deleted first is simply missing
+	rows, err := tx.Query(ctx,
+		`SELECT c.hash FROM chunks c JOIN chunk_owners o ON o.hash = c.hash AND o.owner = $2
+		 WHERE c.hash = ANY($1) AND c.stored_size IS NOT NULL
+		 FOR SHARE OF c`, hashes, owner)
+	if err != nil {
+		return 0, nil, err
+	}
+	present := make(map[string]bool, len(hashes))
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			rows.Close()
+			return 0, nil, err
+		}
+		present[hash] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	var missing []string
+	for _, hash := range hashes {
+		if !present[hash] {
+			present[hash] = true // report repeated chunks once
+			missing = append(missing, hash)
+		}
+	}
+	if len(missing) > 0 {
+		return 0, missing, nil
+	}
+
+	version, err := publishRecipe(ctx, tx, owner, fileName, hashes, size)
+	if err != nil {
+		return 0, nil, err
+	}
+	// The recipe now keeps its chunks alive; the upload sessions that pinned them are done
+	_, err = tx.Exec(ctx, `DELETE FROM upload_sessions WHERE owner = $1 AND file_name = $2`, owner, fileName)
+	if err != nil {
+		return 0, nil, err
+	}
+	return version, nil, tx.Commit(ctx)
+}
+
+// publishRecipe upserts the current recipe and records a version unless the content is unchanged
+func publishRecipe(ctx context.Context, tx pgx.Tx, owner, fileName string, hashes []string, size int64) (int, error) {
+	// An empty file has no chunks, and pgx sends a nil slice as NULL, which chunk_hashes refuses
+	if hashes == nil {
+		hashes = []string{}
+	}
+
+	// The upsert locks the recipe row, which serializes version numbering per file
+	query := `INSERT INTO file_recipes (owner, file_name, chunk_hashes) 
+			  VALUES ($1, $2, $3) 
+			  ON CONFLICT (owner, file_name) 
+			  DO UPDATE SET chunk_hashes = $3, updated_at = CURRENT_TIMESTAMP`
+
 	// pgx handles []string -> TEXT[] automatically
-	_, err := r.Pool.Exec(context.Background(), query, fileName, hashes)
-	return err
+	if _, err := tx.Exec(ctx, query, owner, fileName, hashes); err != nil {
+		return 0, err
+	}
+
+	var latest int
+	var latestHashes []string
+	err := tx.QueryRow(ctx,
+		`SELECT version, chunk_hashes FROM file_versions WHERE owner = $1 AND file_name = $2 ORDER BY version DESC LIMIT 1`,
+		owner, fileName).Scan(&latest, &latestHashes)
+	if err != nil && err != pgx.ErrNoRows {
+		return 0, err
+	}
+	if err == nil && slices.Equal(latestHashes, hashes) {
+		return latest, nil
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO file_versions (owner, file_name, version, chunk_hashes, size) VALUES ($1, $2, $3, $4, $5)`,
+		owner, fileName, latest+1, hashes, size)
+	if err != nil {
+		return 0, err
+	}
+	return latest + 1, nil
+}
+
+// FileVersion is one entry of a file's history
+type FileVersion struct {
+	Version    int
+	CreatedAt  time.Time
+	Size       int64
+	ChunkCount int
 }
 
-// GetAllRecipes retrieves all files for the dashboard
-func (r *RemoteDB) GetAllRecipes() ([]struct{Name string; UpdatedAt time.Time}, error) {
-	rows, err := r.Pool.Query(context.Background(), "SELECT file_name, updated_at FROM file_recipes ORDER BY updated_at DESC")
+// ListVersions returns a file's history, newest first
+func (r *RemoteDB) ListVersions(owner, fileName string) ([]FileVersion, error) {
+	query := `SELECT version, created_at, size, cardinality(chunk_hashes)
+			  FROM file_versions WHERE owner = $1 AND file_name = $2 ORDER BY version DESC`
+	rows, err := r.Pool.Query(context.Background(), query, owner, fileName)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var results []struct{Name string; UpdatedAt time.Time}
+	var versions []FileVersion
 	for rows.Next() {
-		var item struct{Name string; UpdatedAt time.Time}
-		if err := rows.Scan(&item.Name, &item.UpdatedAt); err != nil {
+		var v FileVersion
+		if err := rows.Scan(&v.Version, &v.CreatedAt, &v.Size, &v.ChunkCount); err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+// RestoreVersion makes an old version current again. History stays append-only, so the
+// restored content is recorded as a new version; its number is returned.
+func (r *RemoteDB) RestoreVersion(owner, fileName string, version int) (int, error) {
+	ctx := context.Background()
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var hashes []string
+	var size int64
+	err = tx.QueryRow(ctx,
+		`SELECT chunk_hashes, size FROM file_versions WHERE owner = $1 AND file_name = $2 AND version = $3`,
+		owner, fileName, version).Scan(&hashes, &size)
+	if err != nil {
+		return 0, err
+	}
+
+	restored, err := publishRecipe(ctx, tx, owner, fileName, hashes, size)
+	if err != nil {
+		return 0, err
+	}
+	return restored, tx.Commit(ctx)
+}
+
+// DeleteFileRecipe removes a file from the registry; its chunks stay in place for other recipes
+func (r *RemoteDB) DeleteFileRecipe(owner, fileName string) (bool, error) {
+	tag, err := r.Pool.Exec(context.Background(),
+		`DELETE FROM file_recipes WHERE owner = $1 AND file_name = $2`, owner, fileName)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ChunkRef is one entry of a recipe along with the chunk's metadata
+type ChunkRef struct {
+	Hash    string
+	Size    int   // uncompressed size
+	Codec   int32 // compression of the bytes in the chunk store (pb.Codec)
+	Present bool  // false while the chunk has not been uploaded
+}
+
+// GetChunkRefs looks up the metadata of many chunks in one round trip; unknown hashes are absent
+func (r *RemoteDB) GetChunkRefs(hashes []string) (map[string]ChunkRef, error) {
+	query := `SELECT hash, size, codec FROM chunks WHERE hash = ANY($1) AND stored_size IS NOT NULL`
+	rows, err := r.Pool.Query(context.Background(), query, hashes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refs := make(map[string]ChunkRef, len(hashes))
+	for rows.Next() {
+		ref := ChunkRef{Present: true}
+		if err := rows.Scan(&ref.Hash, &ref.Size, &ref.Codec); err != nil {
+			return nil, err
+		}
+		refs[ref.Hash] = ref
+	}
+	return refs, rows.Err()
+}
+
+// OwnedChunks returns which of the given hashes the owner has uploaded. Chunks are shared across
+// users for dedup, so this is what scopes raw chunk reads.
+func (r *RemoteDB) OwnedChunks(owner string, hashes []string) (map[string]bool, error) {
+	query := `SELECT hash FROM chunk_owners WHERE owner = $1 AND hash = ANY($2)`
+	rows, err := r.Pool.Query(context.Background(), query, owner, hashes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	owned := make(map[string]bool, len(hashes))
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		owned[hash] = true
+	}
+	return owned, rows.Err()
+}
+
+// Recipe is a file's ordered chunk list at a given version
+type Recipe struct {
+	Name      string
+	Version   int
+	Chunks    []ChunkRef
+	UpdatedAt time.Time
+}
+
+// GetFileRecipe returns a file's ordered chunk list with metadata; chunks not uploaded yet report size 0.
+// Version 0 selects the current recipe, anything else a specific entry of the history.
+func (r *RemoteDB) GetFileRecipe(owner, fileName string, version int) (*Recipe, error) {
+	ctx := context.Background()
+	recipe := &Recipe{Name: fileName}
+	var hashes []string
+
+	var err error
+	if version == 0 {
+		query := `SELECT r.chunk_hashes, r.updated_at,
+				  COALESCE((SELECT MAX(v.version) FROM file_versions v
+							WHERE v.owner = r.owner AND v.file_name = r.file_name), 0)
+				  FROM file_recipes r WHERE r.owner = $1 AND r.file_name = $2`
+		err = r.Pool.QueryRow(ctx, query, owner, fileName).Scan(&hashes, &recipe.UpdatedAt, &recipe.Version)
+	} else {
+		query := `SELECT chunk_hashes, created_at, version FROM file_versions
+				  WHERE owner = $1 AND file_name = $2 AND version = $3`
+		err = r.Pool.QueryRow(ctx, query, owner, fileName, version).Scan(&hashes, &recipe.UpdatedAt, &recipe.Version)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// One round trip for all sizes instead of one per chunk
+	refs, err := r.GetChunkRefs(hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	recipe.Chunks = make([]ChunkRef, len(hashes))
+	for i, hash := range hashes {
+		ref := refs[hash]
+		ref.Hash = hash
+		recipe.Chunks[i] = ref
+	}
+	return recipe, nil
+}
+
+// FileRecipe is one entry of the file registry
+type FileRecipe struct {
+	Name      string
+	Hashes    []string
+	UpdatedAt time.Time
+}
+
+// GetAllRecipes retrieves an owner's files for the dashboard and for clients pulling changes
+func (r *RemoteDB) GetAllRecipes(owner string) ([]FileRecipe, error) {
+	rows, err := r.Pool.Query(context.Background(),
+		"SELECT file_name, chunk_hashes, updated_at FROM file_recipes WHERE owner = $1 ORDER BY updated_at DESC", owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []FileRecipe
+	for rows.Next() {
+		var item FileRecipe
+		if err := rows.Scan(&item.Name, &item.Hashes, &item.UpdatedAt); err != nil {
 			return nil, err
 		}
 		results = append(results, item)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 	return results, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// schema lists the tables the server relies on. Every statement is idempotent so existing
+// Neon databases only gain what they are missing.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS chunks (
+		hash TEXT PRIMARY KEY,
+		data BYTEA,
+		size INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS file_recipes (
+		file_name TEXT PRIMARY KEY,
+		chunk_hashes TEXT[] NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`,
+	// append-only history: a row is written whenever a file's recipe changes
+	`CREATE TABLE IF NOT EXISTS file_versions (
+		file_name TEXT NOT NULL,
+		version INTEGER NOT NULL,
+		chunk_hashes TEXT[] NOT NULL,
+		size BIGINT NOT NULL DEFAULT 0,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (file_name, version)
+	)`,
+	// lets garbage collection leave freshly uploaded chunks alone until their recipe lands
+	`ALTER TABLE chunks ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP`,
+	// bytes may live in an external chunk store, leaving only metadata in the row
+	`ALTER TABLE chunks ALTER COLUMN data DROP NOT NULL`,
+	// at-rest compression; size stays the uncompressed size, stored_size is what the store holds.
+	// stored_size is only set by StoreChunk, so a NULL marks a chunk whose upload never finished.
+	`ALTER TABLE chunks ADD COLUMN IF NOT EXISTS codec SMALLINT NOT NULL DEFAULT 0`,
+	`DO $$ BEGIN
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+					   WHERE table_name = 'chunks' AND column_name = 'stored_size') THEN
+			ALTER TABLE chunks ADD COLUMN stored_size INTEGER;
+			-- rows written before compression existed are complete, uncompressed chunks
+			UPDATE chunks SET stored_size = size;
+		END IF;
+	END $$`,
+	// per-user namespaces: file names are only unique within an owner. Files written before
+	// authentication existed belong to the empty owner, which is also used when auth is off.
+	// The old primary keys are looked up by type, since their names depend on how the tables
+	// were first created.
+	`DO $$
+	DECLARE
+		pkey TEXT;
+	BEGIN
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+					   WHERE table_name = 'file_recipes' AND column_name = 'owner') THEN
+			ALTER TABLE file_recipes ADD COLUMN owner TEXT NOT NULL DEFAULT '';
+			SELECT conname INTO pkey FROM pg_constraint
+				WHERE conrelid = 'file_recipes'::regclass AND contype = 'p';
+			IF pkey IS NOT NULL THEN
+				EXECUTE format('ALTER TABLE file_recipes DROP CONSTRAINT %I', pkey);
+			END IF;
+			ALTER TABLE file_recipes ADD PRIMARY KEY (owner, file_name);
+		END IF;
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+					   WHERE table_name = 'file_versions' AND column_name = 'owner') THEN
+			ALTER TABLE file_versions ADD COLUMN owner TEXT NOT NULL DEFAULT '';
+			SELECT conname INTO pkey FROM pg_constraint
+				WHERE conrelid = 'file_versions'::regclass AND contype = 'p';
+			IF pkey IS NOT NULL THEN
+				EXECUTE format('ALTER TABLE file_versions DROP CONSTRAINT %I', pkey);
+			END IF;
+			ALTER TABLE file_versions ADD PRIMARY KEY (owner, file_name, version);
+		END IF;
+	END $$`,
+	// chunks a client was asked to upload, so an interrupted stream can resume where it stopped
+	`CREATE TABLE IF NOT EXISTS upload_sessions (
+		id TEXT PRIMARY KEY,
+		owner TEXT NOT NULL DEFAULT '',
+		file_name TEXT NOT NULL,
+		chunk_hashes TEXT[] NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`,
+	// chunks each owner has a claim to. Bytes are shared across users for dedup, but a chunk may
+	// only be referenced or read by someone who uploaded it, otherwise knowing a hash would be
+	// enough to read another user's data. Claims implied by existing files are backfilled once.
+	`DO $$ BEGIN
+		IF NOT EXISTS (SELECT 1 FROM information_schema.tables WHERE table_name = 'chunk_owners') THEN
+			CREATE TABLE chunk_owners (
+				owner TEXT NOT NULL,
+				hash TEXT NOT NULL,
+				PRIMARY KEY (owner, hash)
+			);
+			INSERT INTO chunk_owners (owner, hash)
+				SELECT owner, unnest(chunk_hashes) FROM file_recipes
+				UNION
+				SELECT owner, unnest(chunk_hashes) FROM file_versions;
+		END IF;
+	END $$`,
+	`CREATE INDEX IF NOT EXISTS chunk_owners_hash ON chunk_owners (hash)`,
+}
+
+// ensureSchema creates any missing tables
+func ensureSchema(pool *pgxpool.Pool) error {
+	for _, stmt := range schema {
+		if _, err := pool.Exec(context.Background(), stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// UploadSessionTTL is how long an upload session survives without activity before it is dropped
+const UploadSessionTTL = 24 * time.Hour
+
+// UploadSession is the set of chunks a client was asked to upload for one file
+type UploadSession struct {
+	ID        string
+	Owner     string
+	FileName  string
+	Hashes    []string // chunks that were missing when the session began, in upload order
+	UpdatedAt time.Time
+}
+
+// CreateUploadSession records the chunks a client is about to upload and returns the session ID.
+// Whether each chunk has arrived is read from the chunks table, so a session never goes stale
+// when the same chunk is uploaded through another session or client. Sessions keep their chunks
+// safe from garbage collection until CommitFileRecipe publishes the file or the session expires.
+func (r *RemoteDB) CreateUploadSession(owner, fileName string, hashes []string) (string, error) {
+	ctx := context.Background()
+
+	// Abandoned sessions are cleaned up opportunistically; there are few of them at any time
+	_, err := r.Pool.Exec(ctx, `DELETE FROM upload_sessions WHERE updated_at < $1`, time.Now().Add(-UploadSessionTTL))
+	if err != nil {
+		return "", err
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	sessionID := hex.EncodeToString(id)
+
+	_, err = r.Pool.Exec(ctx,
+		`INSERT INTO upload_sessions (id, owner, file_name, chunk_hashes) VALUES ($1, $2, $3, $4)`,
+		sessionID, owner, fileName, hashes)
+	if err != nil {
+		return "", err
+	}
+	return sessionID, nil
+}
+
+// GetUploadSession loads one of the owner's sessions and marks it as active
+func (r *RemoteDB) GetUploadSession(owner, sessionID string) (*UploadSession, error) {
+	query := `UPDATE upload_sessions SET updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $1 AND owner = $2
+			  RETURNING id, owner, file_name, chunk_hashes, updated_at`
+	session := &UploadSession{}
+	err := r.Pool.QueryRow(context.Background(), query, sessionID, owner).
+		Scan(&session.ID, &session.Owner, &session.FileName, &session.Hashes, &session.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
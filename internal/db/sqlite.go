@@ -2,43 +2,443 @@ package db
 
 import(
 	"database/sql"
-	"log"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	_ "github.com/glebarez/go-sqlite"  //CGO-free driver
 )
 
 type LocalDB struct{
 	Conn *sql.DB
+	// CacheLimit caps the bytes kept in chunk_cache; TrimCache evicts least recently used chunks beyond it.
+	// Zero or less keeps everything.
+	CacheLimit int64
+	// cachedSinceTrim counts bytes CacheChunk added since the cache was last trimmed
+	cachedSinceTrim atomic.Int64
+
+	// pinned counts, per hash, the operations that still need a chunk in the cache
+	pinMu  sync.Mutex
+	pinned map[string]int
+}
+
+// DefaultCacheLimit is the chunk cache size used unless the client configures another
+const DefaultCacheLimit = 512 << 20
+
+// FileEntry is what the client recorded about a file the last time it was synced
+type FileEntry struct {
+	// Size, ModTime and Inode identify the on-disk state that was chunked
+	Size    int64
+	ModTime time.Time
+	Inode   uint64
+	Chunks  []IndexedChunk
+	// SyncedAt is set by SaveFileIndex
+	SyncedAt time.Time
+}
+
+// IndexedChunk is one entry of a file's recipe in order
+type IndexedChunk struct {
+	Hash        string // the chunk's name on the server
+	ContentHash string // SHA-256 of the plaintext; equals Hash unless chunks are encrypted, empty if unknown
+	Size        int    // plaintext length; 0 if unknown
+}
+
+// Hashes returns the recipe as the server knows it
+func (e FileEntry) Hashes() []string {
+	hashes := make([]string, len(e.Chunks))
+	for i, c := range e.Chunks {
+		hashes[i] = c.Hash
+	}
+	return hashes
+}
+
+// Matches reports whether a file on disk is still exactly what was synced, judged by metadata alone
+func (e FileEntry) Matches(size int64, modTime time.Time, inode uint64) bool {
+	return e.Size == size && e.ModTime.Equal(modTime) && e.Inode == inode
 }
 
-// InitSQLite sets up the local database file
-func InitSQLite(dbPath string) *LocalDB{
-	// open or create the sqlite file
-	db, err := sql.Open("sqlite", dbPath)
-	if err != nil{
-		log.Fatal(err)
+// InitSQLite opens the local database file, creating and migrating its tables as needed
+func InitSQLite(dbPath string) (*LocalDB, error) {
+	// open or create the sqlite file; concurrent syncs wait for each other's writes instead of
+	// failing with "database is locked"
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", dbPath, err)
 	}
 
-	// create a table to store file metadata and their chunk recipes
-	// we store the hashes as a comma-separated string for simplicity\
+	// create a table to store file metadata, and one holding each file's chunk recipe in order
 	query := `
 	CREATE TABLE IF NOT EXISTS file_index (
 			path TEXT PRIMARY KEY,
 			last_modified DATETIME,
-			chunk_hashes TEXT
+			size INTEGER NOT NULL DEFAULT 0,
+			mtime INTEGER NOT NULL DEFAULT 0,
+			inode INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE TABLE IF NOT EXISTS file_chunks (
+			path TEXT NOT NULL,
+			position INTEGER NOT NULL,
+			hash TEXT NOT NULL,
+			content_hash TEXT NOT NULL DEFAULT '',
+			size INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (path, position)
 	);`
 
 	_, err = db.Exec(query)
 	if err != nil {
-		log.Fatal(err)
+		db.Close()
+		return nil, fmt.Errorf("creating tables in %s: %w", dbPath, err)
 	}
 
-	return &LocalDB{Conn: db}
+	// content-addressed copies of chunks seen locally, so pulls can skip downloading them.
+	// last_used (unix nanoseconds) orders LRU eviction.
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS chunk_cache (
+			hash TEXT PRIMARY KEY,
+			data BLOB,
+			size INTEGER NOT NULL DEFAULT 0,
+			last_used INTEGER NOT NULL DEFAULT 0
+	);`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating chunk cache in %s: %w", dbPath, err)
+	}
+
+	if err := migrateSQLite(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrating %s: %w", dbPath, err)
+	}
+
+	return &LocalDB{Conn: db, CacheLimit: DefaultCacheLimit}, nil
 }
 
-// SaveFileIndex stores the file's current state
-func (db *LocalDB) SaveFileIndex(path string, hashes string) error {
-	query := `INSERT OR REPLACE INTO file_index (path, last_modified, chunk_hashes) VALUES (?, CURRENT_TIMESTAMP, ?);`
-	_, err := db.Conn.Exec(query, path, hashes)
+// addedColumns were introduced after their tables first shipped
+var addedColumns = []struct{ table, column, decl string }{
+	{"file_index", "size", "INTEGER NOT NULL DEFAULT 0"},
+	{"file_index", "mtime", "INTEGER NOT NULL DEFAULT 0"},
+	{"file_index", "inode", "INTEGER NOT NULL DEFAULT 0"},
+	{"file_chunks", "content_hash", "TEXT NOT NULL DEFAULT ''"},
+	{"file_chunks", "size", "INTEGER NOT NULL DEFAULT 0"},
+	{"chunk_cache", "size", "INTEGER NOT NULL DEFAULT 0"},
+	{"chunk_cache", "last_used", "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// migrateSQLite upgrades databases written by older clients: tables gain the columns added since,
+// and recipes kept as a comma-separated chunk_hashes column move into file_chunks
+func migrateSQLite(db *sql.DB) error {
+	for _, c := range addedColumns {
+		ok, err := hasColumn(db, c.table, c.column)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if _, err := db.Exec(`ALTER TABLE ` + c.table + ` ADD COLUMN ` + c.column + ` ` + c.decl + `;`); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := db.Exec(`UPDATE chunk_cache SET size = length(data) WHERE size = 0;`); err != nil {
+		return err
+	}
+
+	csv, err := hasColumn(db, "file_index", "chunk_hashes")
+	if err != nil {
+		return err
+	}
+	if csv {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		rows, err := tx.Query(`SELECT path, COALESCE(chunk_hashes, '') FROM file_index;`)
+		if err != nil {
+			return err
+		}
+		// Sizes and plaintext hashes were never recorded, so these recipes cannot seed Rechunk
+		recipes := make(map[string][]IndexedChunk)
+		for rows.Next() {
+			var path, hashes string
+			if err := rows.Scan(&path, &hashes); err != nil {
+				rows.Close()
+				return err
+			}
+			if hashes != "" {
+				for _, hash := range strings.Split(hashes, ",") {
+					recipes[path] = append(recipes[path], IndexedChunk{Hash: hash})
+				}
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for path, hashes := range recipes {
+			if err := insertFileChunks(tx, path, hashes); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(`ALTER TABLE file_index DROP COLUMN chunk_hashes;`); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+	return nil
+}
+
+// hasColumn reports whether a table already has the named column
+func hasColumn(db *sql.DB, table, column string) (bool, error) {
+	var n int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?;`, table, column).Scan(&n)
+	return n > 0, err
+}
+
+// DeleteFileIndex forgets a file that was removed locally
+func (db *LocalDB) DeleteFileIndex(path string) error {
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM file_chunks WHERE path = ?;`, path); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM file_index WHERE path = ?;`, path); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetFileIndex returns what was recorded for a file at its last sync, including its ordered recipe
+func (db *LocalDB) GetFileIndex(path string) (FileEntry, bool, error) {
+	var e FileEntry
+	var mtime, inode int64
+	err := db.Conn.QueryRow(`SELECT last_modified, size, mtime, inode FROM file_index WHERE path = ?;`, path).
+		Scan(&e.SyncedAt, &e.Size, &mtime, &inode)
+	if err == sql.ErrNoRows {
+		return FileEntry{}, false, nil
+	}
+	if err != nil {
+		return FileEntry{}, false, err
+	}
+	if mtime != 0 {
+		e.ModTime = time.Unix(0, mtime)
+	}
+	e.Inode = uint64(inode)
+
+	rows, err := db.Conn.Query(`SELECT hash, content_hash, size FROM file_chunks WHERE path = ? ORDER BY position;`, path)
+	if err != nil {
+		return FileEntry{}, false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c IndexedChunk
+		if err := rows.Scan(&c.Hash, &c.ContentHash, &c.Size); err != nil {
+			return FileEntry{}, false, err
+		}
+		e.Chunks = append(e.Chunks, c)
+	}
+	if err := rows.Err(); err != nil {
+		return FileEntry{}, false, err
+	}
+	return e, true, nil
+}
+
+// IndexedPathsUnder lists every indexed file inside the given directory
+func (db *LocalDB) IndexedPathsUnder(dir string) ([]string, error) {
+	prefix := strings.TrimSuffix(dir, string(filepath.Separator)) + string(filepath.Separator)
+	rows, err := db.Conn.Query(`SELECT path FROM file_index;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(path, prefix) {
+			paths = append(paths, path)
+		}
+	}
+	return paths, rows.Err()
+}
+
+// SaveFileIndex stores the file's current state and its chunk recipe
+func (db *LocalDB) SaveFileIndex(path string, e FileEntry) error {
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var mtime int64
+	if !e.ModTime.IsZero() {
+		mtime = e.ModTime.UnixNano()
+	}
+	query := `INSERT OR REPLACE INTO file_index (path, last_modified, size, mtime, inode) VALUES (?, ?, ?, ?, ?);`
+	if _, err := tx.Exec(query, path, time.Now().UTC(), e.Size, mtime, int64(e.Inode)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM file_chunks WHERE path = ?;`, path); err != nil {
+		return err
+	}
+	if err := insertFileChunks(tx, path, e.Chunks); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertFileChunks writes a file's recipe, one row per chunk position
+func insertFileChunks(tx *sql.Tx, path string, chunks []IndexedChunk) error {
+	stmt, err := tx.Prepare(`INSERT INTO file_chunks (path, position, hash, content_hash, size) VALUES (?, ?, ?, ?, ?);`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i, c := range chunks {
+		if _, err := stmt.Exec(path, i, c.Hash, c.ContentHash, c.Size); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CacheChunk keeps a local copy of a chunk's bytes under its hash and marks it recently used.
+// The cache is trimmed whenever an eighth of CacheLimit has been added, so a large file passing
+// through it never grows it far beyond the limit; see Pin for chunks that must survive that.
+func (db *LocalDB) CacheChunk(hash string, data []byte) error {
+	_, err := db.Conn.Exec(`
+		INSERT INTO chunk_cache (hash, data, size, last_used) VALUES (?, ?, ?, ?)
+		ON CONFLICT (hash) DO UPDATE SET last_used = excluded.last_used;`,
+		hash, data, len(data), time.Now().UnixNano())
+	if err != nil || db.CacheLimit <= 0 {
+		return err
+	}
+	if db.cachedSinceTrim.Add(int64(len(data))) >= max(db.CacheLimit/8, 1) {
+		_, err = db.TrimCache()
+	}
 	return err
-}
\ No newline at end of file
+}
+
+// HasCachedChunk reports whether a chunk is in the local cache without loading its bytes.
+// A hit counts as a use, since the caller is about to rely on it.
+func (db *LocalDB) HasCachedChunk(hash string) (bool, error) {
+	res, err := db.Conn.Exec(`UPDATE chunk_cache SET last_used = ? WHERE hash = ?;`, time.Now().UnixNano(), hash)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// CachedChunk returns the locally cached bytes for a hash, if any
+func (db *LocalDB) CachedChunk(hash string) ([]byte, bool, error) {
+	var data []byte
+	err := db.Conn.QueryRow(`
+		UPDATE chunk_cache SET last_used = ? WHERE hash = ? RETURNING data;`,
+		time.Now().UnixNano(), hash).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// Pin keeps the given chunks out of TrimCache's reach until the returned function is called.
+// A restore pins its chunks before looking them up so none is evicted before the file is
+// written out, even when the file alone is larger than CacheLimit. Pins are counted, so
+// operations sharing a chunk each keep it.
+func (db *LocalDB) Pin(hashes []string) (unpin func()) {
+	db.pinMu.Lock()
+	defer db.pinMu.Unlock()
+	if db.pinned == nil {
+		db.pinned = make(map[string]int)
+	}
+	for _, hash := range hashes {
+		db.pinned[hash]++
+	}
+	return func() {
+		db.pinMu.Lock()
+		defer db.pinMu.Unlock()
+		for _, hash := range hashes {
+			if db.pinned[hash]--; db.pinned[hash] <= 0 {
+				delete(db.pinned, hash)
+			}
+		}
+	}
+}
+
+// TrimCache evicts the least recently used chunks until the cache fits within CacheLimit.
+// Pinned chunks are never evicted, though they count towards the limit, so the cache can stay
+// above it while a restore larger than the limit is in flight.
+func (db *LocalDB) TrimCache() (int64, error) {
+	if db.CacheLimit <= 0 {
+		return 0, nil
+	}
+	db.cachedSinceTrim.Store(0)
+
+	// Pinning waits for the trim, so nothing is pinned between choosing and evicting
+	db.pinMu.Lock()
+	defer db.pinMu.Unlock()
+
+	rows, err := db.Conn.Query(`SELECT hash, size FROM chunk_cache ORDER BY last_used DESC, hash;`)
+	if err != nil {
+		return 0, err
+	}
+	var evict []string
+	var kept int64
+	for rows.Next() {
+		var hash string
+		var size int64
+		if err := rows.Scan(&hash, &size); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if kept+size > db.CacheLimit && db.pinned[hash] == 0 {
+			evict = append(evict, hash)
+			continue
+		}
+		kept += size
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(evict) == 0 {
+		return 0, err
+	}
+
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.Prepare(`DELETE FROM chunk_cache WHERE hash = ?;`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+	for _, hash := range evict {
+		if _, err := stmt.Exec(hash); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(evict)), tx.Commit()
+}
+
+// CacheUsage returns how many chunks and bytes the local cache currently holds
+func (db *LocalDB) CacheUsage() (int64, int64, error) {
+	var count, size int64
+	err := db.Conn.QueryRow(`SELECT COUNT(*), COALESCE(SUM(size), 0) FROM chunk_cache;`).Scan(&count, &size)
+	return count, size, err
+}
@@ -0,0 +1,106 @@
+package encrypt
+
+import (
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// KeySize is the length of a user's master key
+const KeySize = 32
+
+// version prefixes every sealed chunk so the format can evolve
+const version byte = 1
+
+// Overhead is how many bytes Seal adds to a chunk
+const Overhead = 1 + chacha20poly1305.NonceSizeX + chacha20poly1305.Overhead
+
+// Sealer encrypts chunks with XChaCha20-Poly1305 under a per-user key. The nonce is derived from
+// the plaintext with a keyed MAC, so identical chunks seal to identical ciphertext for the same
+// key: dedup keeps working within a tenant while other tenants and the server learn nothing but
+// equality. The server never sees the key; it hashes and stores the sealed bytes like any chunk.
+type Sealer struct {
+	aead     cipher.AEAD
+	nonceKey []byte
+}
+
+// NewSealer derives independent encryption and nonce keys from the master key
+func NewSealer(key []byte) (*Sealer, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	encKey, err := hkdf.Key(sha256.New, key, nil, "delta-sync chunk encryption", chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	nonceKey, err := hkdf.Key(sha256.New, key, nil, "delta-sync chunk nonce", 32)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := chacha20poly1305.NewX(encKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Sealer{aead: aead, nonceKey: nonceKey}, nil
+}
+
+// Seal encrypts a plaintext chunk: version || nonce || ciphertext+tag
+func (s *Sealer) Seal(plain []byte) []byte {
+	mac := hmac.New(sha256.New, s.nonceKey)
+	mac.Write(plain)
+	nonce := mac.Sum(nil)[:chacha20poly1305.NonceSizeX]
+
+	out := make([]byte, 0, 1+len(nonce)+len(plain)+s.aead.Overhead())
+	out = append(out, version)
+	out = append(out, nonce...)
+	return s.aead.Seal(out, nonce, plain, []byte{version})
+}
+
+// Open decrypts and authenticates a chunk produced by Seal
+func (s *Sealer) Open(sealed []byte) ([]byte, error) {
+	headerSize := 1 + chacha20poly1305.NonceSizeX
+	if len(sealed) < headerSize+s.aead.Overhead() {
+		return nil, errors.New("sealed chunk too short")
+	}
+	if sealed[0] != version {
+		return nil, fmt.Errorf("unsupported sealed chunk version %d", sealed[0])
+	}
+
+	nonce := sealed[1:headerSize]
+	plain, err := s.aead.Open(nil, nonce, sealed[headerSize:], []byte{version})
+	if err != nil {
+		return nil, errors.New("chunk failed authentication (wrong key or tampered data)")
+	}
+	return plain, nil
+}
+
+// ParseKey decodes a hex-encoded master key, e.g. the output of `openssl rand -hex 32`
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := hex.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("encryption key is not valid hex: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes (%d hex characters), got %d", KeySize, KeySize*2, len(key))
+	}
+	return key, nil
+}
+
+// LoadKey reads a hex-encoded master key from a file
+func LoadKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKey(string(data))
+}
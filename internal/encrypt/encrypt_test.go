@@ -0,0 +1,124 @@
+package encrypt
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testSealer(t *testing.T, fill byte) *Sealer {
+	t.Helper()
+	s, err := NewSealer(bytes.Repeat([]byte{fill}, KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestRoundTrip(t *testing.T) {
+	s := testSealer(t, 1)
+	for _, plain := range [][]byte{
+		{},
+		[]byte("a"),
+		[]byte("hello, delta-sync"),
+		bytes.Repeat([]byte{0xAB}, 64<<10),
+	} {
+		sealed := s.Seal(plain)
+		if len(sealed) != len(plain)+Overhead {
+			t.Errorf("sealed %d bytes into %d, want %d", len(plain), len(sealed), len(plain)+Overhead)
+		}
+		if len(plain) > 0 && bytes.Contains(sealed, plain) {
+			t.Errorf("sealed chunk contains its plaintext")
+		}
+		got, err := s.Open(sealed)
+		if err != nil {
+			t.Fatalf("Open(%d bytes): %v", len(plain), err)
+		}
+		if !bytes.Equal(got, plain) {
+			t.Fatalf("round trip of %d bytes returned different data", len(plain))
+		}
+	}
+}
+
+func TestConvergent(t *testing.T) {
+	s := testSealer(t, 1)
+	plain := []byte("the same chunk twice")
+
+	if !bytes.Equal(s.Seal(plain), s.Seal(bytes.Clone(plain))) {
+		t.Fatal("equal plaintexts sealed to different ciphertext under one key")
+	}
+	if bytes.Equal(s.Seal(plain), s.Seal([]byte("the same chunk twicE"))) {
+		t.Fatal("different plaintexts sealed to the same ciphertext")
+	}
+	// A second Sealer from the same key must agree, or dedup breaks across restarts
+	if !bytes.Equal(s.Seal(plain), testSealer(t, 1).Seal(plain)) {
+		t.Fatal("sealers built from the same key disagree")
+	}
+	if bytes.Equal(s.Seal(plain), testSealer(t, 2).Seal(plain)) {
+		t.Fatal("different keys sealed to the same ciphertext")
+	}
+}
+
+func TestOpenRejects(t *testing.T) {
+	s := testSealer(t, 1)
+	sealed := s.Seal([]byte("some chunk contents"))
+
+	flip := func(i int) []byte {
+		b := bytes.Clone(sealed)
+		b[i] ^= 0x01
+		return b
+	}
+	tests := []struct {
+		name   string
+		sealer *Sealer
+		sealed []byte
+	}{
+		{"wrong key", testSealer(t, 2), sealed},
+		{"flipped nonce", s, flip(1)},
+		{"flipped ciphertext", s, flip(Overhead)},
+		{"flipped tag", s, flip(len(sealed) - 1)},
+		{"unknown version", s, flip(0)},
+		{"trailing byte", s, append(bytes.Clone(sealed), 0)},
+		{"dropped byte", s, sealed[:len(sealed)-1]},
+		{"shorter than overhead", s, sealed[:Overhead-1]},
+		{"empty", s, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if plain, err := tt.sealer.Open(tt.sealed); err == nil {
+				t.Fatalf("opened to %q", plain)
+			}
+		})
+	}
+}
+
+func TestNewSealerKeySize(t *testing.T) {
+	for _, n := range []int{0, KeySize - 1, KeySize + 1} {
+		if _, err := NewSealer(make([]byte, n)); err == nil {
+			t.Errorf("accepted a %d-byte key", n)
+		}
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	hexKey := strings.Repeat("0f", KeySize)
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte(hexKey+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	key, err := LoadKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(key, bytes.Repeat([]byte{0x0f}, KeySize)) {
+		t.Fatalf("LoadKey = %x", key)
+	}
+
+	for _, bad := range []string{"", "zz", hexKey[:len(hexKey)-2], hexKey + "00"} {
+		if _, err := ParseKey(bad); err == nil {
+			t.Errorf("ParseKey(%q) accepted", bad)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Meta identifies the sync an event belongs to
+type Meta struct {
+	Owner string // namespace of the uploading user ("" when authentication is off)
+	File  string
+	At    time.Time
+}
+
+// Info returns the event's metadata; it is what makes every event type an Event
+func (m Meta) Info() Meta {
+	return m
+}
+
+// Event is one of SyncStarted, ChunkStored, SyncCompleted or SyncFailed
+type Event interface {
+	Info() Meta
+}
+
+// SyncStarted is published when an upload stream has announced what it will send
+type SyncStarted struct {
+	Meta
+	Chunks int
+	Bytes  int64
+}
+
+// ChunkStored is published for every chunk committed during an upload, with running totals
+type ChunkStored struct {
+	Meta
+	Hash           string
+	Size           int // uncompressed size
+	ChunksDone     int
+	ChunksTotal    int
+	BytesDone      int64
+	BytesTotal     int64
+	BytesPerSecond float64
+	ETA            time.Duration
+}
+
+// SyncCompleted is published when an upload stream finished cleanly
+type SyncCompleted struct {
+	Meta
+	Chunks   int
+	Bytes    int64
+	Duration time.Duration
+}
+
+// SyncFailed is published when an upload stream ended with an error
+type SyncFailed struct {
+	Meta
+	Err        string
+	ChunksDone int
+}
+
+// Bus fans published events out to subscribers inside one process
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+// SubscriptionBuffer is how many events a subscriber may fall behind before new ones are dropped
+const SubscriptionBuffer = 256
+
+// Subscription delivers the events matching its filter on C until Close is called
+type Subscription struct {
+	C <-chan Event
+
+	ch     chan Event
+	filter func(Event) bool
+	bus    *Bus
+	once   sync.Once
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: make(map[*Subscription]struct{})}
+}
+
+// Publish hands an event to every matching subscriber. It never blocks: a subscriber whose
+// buffer is full misses the event rather than stalling the upload that produced it.
+func (b *Bus) Publish(e Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.subs {
+		if sub.filter != nil && !sub.filter(e) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+		}
+	}
+}
+
+// Subscribe starts receiving events accepted by filter; a nil filter receives everything
+func (b *Bus) Subscribe(filter func(Event) bool) *Subscription {
+	ch := make(chan Event, SubscriptionBuffer)
+	sub := &Subscription{C: ch, ch: ch, filter: filter, bus: b}
+
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+	return sub
+}
+
+// Close stops delivery and closes C
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.mu.Lock()
+		delete(s.bus.subs, s)
+		s.bus.mu.Unlock()
+		close(s.ch)
+	})
+}
+
+// ForOwner is a filter that only passes events from one user's namespace
+func ForOwner(owner string) func(Event) bool {
+	return func(e Event) bool {
+		return e.Info().Owner == owner
+	}
+}
+
+// Percent is how much of the announced bytes have been stored, capped at 100
+func (c ChunkStored) Percent() float64 {
+	if c.BytesTotal <= 0 {
+		return 0
+	}
+	return min(100, float64(c.BytesDone)/float64(c.BytesTotal)*100)
+}
@@ -0,0 +1,80 @@
+package events
+
+import (
+	"delta-sync/delta-sync-pb/pkg/pb"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ToProto converts an event for the WatchEvents stream; the owner is implied by the caller
+func ToProto(e Event) *pb.SyncEvent {
+	meta := e.Info()
+	out := &pb.SyncEvent{FileId: meta.File, At: timestamppb.New(meta.At)}
+
+	switch e := e.(type) {
+	case SyncStarted:
+		out.Kind = &pb.SyncEvent_Started{Started: &pb.SyncStartedEvent{
+			ChunkCount: int32(e.Chunks),
+			TotalBytes: e.Bytes,
+		}}
+	case ChunkStored:
+		out.Kind = &pb.SyncEvent_ChunkStored{ChunkStored: &pb.ChunkStoredEvent{
+			Hash:           e.Hash,
+			Size:           int32(e.Size),
+			ChunksDone:     int32(e.ChunksDone),
+			ChunksTotal:    int32(e.ChunksTotal),
+			BytesDone:      e.BytesDone,
+			BytesTotal:     e.BytesTotal,
+			BytesPerSecond: e.BytesPerSecond,
+			EtaSeconds:     e.ETA.Seconds(),
+		}}
+	case SyncCompleted:
+		out.Kind = &pb.SyncEvent_Completed{Completed: &pb.SyncCompletedEvent{
+			ChunkCount: int32(e.Chunks),
+			TotalBytes: e.Bytes,
+			Seconds:    e.Duration.Seconds(),
+		}}
+	case SyncFailed:
+		out.Kind = &pb.SyncEvent_Failed{Failed: &pb.SyncFailedEvent{
+			Error:      e.Err,
+			ChunksDone: int32(e.ChunksDone),
+		}}
+	}
+	return out
+}
+
+// FromProto rebuilds an event received from WatchEvents; it returns nil for kinds it doesn't know
+func FromProto(owner string, in *pb.SyncEvent) Event {
+	meta := Meta{Owner: owner, File: in.FileId, At: in.At.AsTime()}
+
+	switch {
+	case in.GetStarted() != nil:
+		e := in.GetStarted()
+		return SyncStarted{Meta: meta, Chunks: int(e.ChunkCount), Bytes: e.TotalBytes}
+	case in.GetChunkStored() != nil:
+		e := in.GetChunkStored()
+		return ChunkStored{
+			Meta:           meta,
+			Hash:           e.Hash,
+			Size:           int(e.Size),
+			ChunksDone:     int(e.ChunksDone),
+			ChunksTotal:    int(e.ChunksTotal),
+			BytesDone:      e.BytesDone,
+			BytesTotal:     e.BytesTotal,
+			BytesPerSecond: e.BytesPerSecond,
+			ETA:            seconds(e.EtaSeconds),
+		}
+	case in.GetCompleted() != nil:
+		e := in.GetCompleted()
+		return SyncCompleted{Meta: meta, Chunks: int(e.ChunkCount), Bytes: e.TotalBytes, Duration: seconds(e.Seconds)}
+	case in.GetFailed() != nil:
+		e := in.GetFailed()
+		return SyncFailed{Meta: meta, Err: e.Error, ChunksDone: int(e.ChunksDone)}
+	}
+	return nil
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
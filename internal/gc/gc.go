@@ -0,0 +1,125 @@
+package gc
+
+import (
+	"context"
+	"delta-sync/internal/db"
+	"delta-sync/internal/store"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Options controls a garbage collection pass
+type Options struct {
+	DryRun       bool          // only report what would be reclaimed
+	BatchSize    int           // chunks examined and deleted per round trip
+	GracePeriod  time.Duration // chunks younger than this are never collected
+	KeepVersions int           // history entries kept per file; 0 keeps everything
+}
+
+// DefaultOptions keeps the whole history and gives in-flight uploads an hour to publish their recipe
+var DefaultOptions = Options{
+	BatchSize:   1000,
+	GracePeriod: time.Hour,
+}
+
+// Report summarizes a garbage collection pass
+type Report struct {
+	DryRun         bool
+	PrunedVersions int64
+	OrphanChunks   int
+	ReclaimedBytes int64
+	Duration       time.Duration
+}
+
+func (r *Report) String() string {
+	verb := "Reclaimed"
+	if r.DryRun {
+		verb = "Reclaimable"
+	}
+	return fmt.Sprintf("%s: %d orphaned chunks, %d bytes, %d pruned versions (took %s)",
+		verb, r.OrphanChunks, r.ReclaimedBytes, r.PrunedVersions, r.Duration.Round(time.Millisecond))
+}
+
+// Run computes reachability from file_recipes and the retained file_versions and deletes
+// every chunk outside it, one batch at a time. Metadata goes first, then the bytes in chunkStore,
+// which are kept if the chunk was uploaded again in between.
+func Run(remoteDB *db.RemoteDB, chunkStore store.ChunkStore, opts Options) (*Report, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultOptions.BatchSize
+	}
+	start := time.Now()
+	report := &Report{DryRun: opts.DryRun}
+
+	// 1. Drop history beyond the retention window so its chunks become unreachable
+	var err error
+	if opts.DryRun {
+		report.PrunedVersions, err = remoteDB.CountPrunableVersions(opts.KeepVersions)
+	} else {
+		report.PrunedVersions, err = remoteDB.PruneVersions(opts.KeepVersions)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pruning versions: %w", err)
+	}
+
+	// 2. Page through orphans by hash; deleted rows simply disappear from later pages
+	cutoff := start.Add(-opts.GracePeriod)
+	after := ""
+	for {
+		orphans, err := remoteDB.FindOrphanChunks(opts.KeepVersions, cutoff, after, opts.BatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("scanning chunks: %w", err)
+		}
+		if len(orphans) == 0 {
+			break
+		}
+		after = orphans[len(orphans)-1].Hash
+
+		if opts.DryRun {
+			for _, o := range orphans {
+				report.OrphanChunks++
+				report.ReclaimedBytes += int64(o.Size)
+			}
+			continue
+		}
+
+		hashes := make([]string, len(orphans))
+		for i, o := range orphans {
+			hashes[i] = o.Hash
+		}
+		deleted, err := remoteDB.DeleteOrphanChunks(opts.KeepVersions, hashes)
+		if err != nil {
+			return nil, fmt.Errorf("deleting chunks: %w", err)
+		}
+		for _, o := range deleted {
+			// Without metadata nobody can reach these bytes any more, so a failed delete
+			// only leaks storage; it is logged rather than aborting the pass
+			err := remoteDB.DeleteChunkBytes(o.Hash, func() error {
+				return chunkStore.Delete(context.Background(), o.Hash)
+			})
+			if err != nil {
+				log.Printf("⚠️  Could not delete chunk %s from store: %v", o.Hash, err)
+			}
+			report.OrphanChunks++
+			report.ReclaimedBytes += int64(o.Size)
+		}
+	}
+
+	report.Duration = time.Since(start)
+	return report, nil
+}
+
+// Schedule runs a pass every interval for the lifetime of the process
+func Schedule(remoteDB *db.RemoteDB, chunkStore store.ChunkStore, interval time.Duration, opts Options) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		report, err := Run(remoteDB, chunkStore, opts)
+		if err != nil {
+			log.Printf("❌ Garbage collection failed: %v", err)
+			continue
+		}
+		fmt.Printf("🧹 Garbage collection done. %s\n", report)
+	}
+}
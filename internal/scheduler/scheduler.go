@@ -0,0 +1,265 @@
+package scheduler
+
+import (
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Defaults used for zero Options fields
+const (
+	DefaultDebounce = 500 * time.Millisecond
+	DefaultMaxWait  = 30 * time.Second
+	DefaultWorkers  = 4
+)
+
+// Options tune how eagerly changes are synced
+type Options struct {
+	// Debounce is how long a path must go without events, and without its size or mtime moving,
+	// before it is queued
+	Debounce time.Duration
+	// MaxWait bounds the wait for a file that never settles (e.g. a growing log), so it still syncs
+	MaxWait time.Duration
+	// Workers is how many syncs may run at once
+	Workers int
+}
+
+// State is where a path currently sits in the scheduler
+type State int
+
+const (
+	Debouncing State = iota // collecting events until the file settles
+	Queued                  // waiting for a free worker
+	Running                 // being synced; new events mark it for another pass
+)
+
+func (s State) String() string {
+	switch s {
+	case Debouncing:
+		return "debouncing"
+	case Queued:
+		return "queued"
+	case Running:
+		return "running"
+	}
+	return "unknown"
+}
+
+// Job describes one path known to the scheduler
+type Job struct {
+	Path   string
+	State  State
+	Since  time.Time // when the path entered its current state
+	Events int       // events coalesced into this job
+	Again  bool      // changed while running, so it is synced once more afterwards
+}
+
+// Scheduler turns a stream of filesystem events into syncs. Events for the same path are
+// coalesced, a path is only queued once its size and mtime have stopped moving, and at most
+// Workers syncs run at a time, never two for the same path. Removals are scheduled like any
+// other change: an editor that saves by renaming a temp file over the original produces a
+// remove and a create within one debounce period, so run sees a file that simply changed.
+// run must therefore cope with paths that no longer exist.
+type Scheduler struct {
+	run  func(path string)
+	opts Options
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	jobs    map[string]*job
+	queue   []string
+	closed  bool
+	workers sync.WaitGroup
+
+	// exclusive lets Exclusive wait out running syncs and hold new ones back
+	exclusive sync.RWMutex
+}
+
+type job struct {
+	Job
+	first, last time.Time // first and latest event of the current burst
+	size        int64
+	modTime     time.Time
+	timer       *time.Timer
+}
+
+// New starts the worker pool; run is called with each path that needs syncing
+func New(run func(path string), opts Options) *Scheduler {
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultDebounce
+	}
+	if opts.MaxWait <= 0 {
+		opts.MaxWait = DefaultMaxWait
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultWorkers
+	}
+
+	s := &Scheduler{run: run, opts: opts, jobs: make(map[string]*job)}
+	s.cond = sync.NewCond(&s.mu)
+	for i := 0; i < opts.Workers; i++ {
+		s.workers.Add(1)
+		go s.worker()
+	}
+	return s
+}
+
+// Touch records a change to path. It never blocks on a running sync.
+func (s *Scheduler) Touch(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	now := time.Now()
+	j, ok := s.jobs[path]
+	if !ok {
+		j = &job{Job: Job{Path: path, State: Debouncing, Since: now}, first: now}
+		s.jobs[path] = j
+	}
+	j.Events++
+
+	switch j.State {
+	case Debouncing:
+		j.last = now
+		j.observe(path)
+		if j.timer == nil {
+			j.timer = time.AfterFunc(s.opts.Debounce, func() { s.settle(path) })
+		}
+	case Running:
+		j.Again = true
+	}
+	// Queued: the sync has not started yet, so it will already see this change
+}
+
+// observe snapshots the file's size and mtime, which must hold still for it to count as settled.
+// A missing file keeps its last snapshot; it only has to stay quiet.
+func (j *job) observe(path string) {
+	if info, err := os.Stat(path); err == nil {
+		j.size, j.modTime = info.Size(), info.ModTime()
+	}
+}
+
+// settle runs when a debounce timer fires and queues the path if it has gone quiet
+func (s *Scheduler) settle(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[path]
+	if !ok || j.State != Debouncing || s.closed {
+		return
+	}
+
+	now := time.Now()
+	quietFor := now.Sub(j.last)
+	moved := false
+	if info, err := os.Stat(path); err == nil {
+		moved = info.Size() != j.size || !info.ModTime().Equal(j.modTime)
+		j.size, j.modTime = info.Size(), info.ModTime()
+	}
+	if (quietFor < s.opts.Debounce || moved) && now.Sub(j.first) < s.opts.MaxWait {
+		// Still being written: look again once it has been quiet for a full debounce period
+		if moved {
+			j.last, quietFor = now, 0
+		}
+		j.timer.Reset(s.opts.Debounce - quietFor)
+		return
+	}
+
+	j.timer = nil
+	j.State, j.Since = Queued, now
+	s.queue = append(s.queue, path)
+	s.cond.Signal()
+}
+
+// worker syncs queued paths until the scheduler is closed
+func (s *Scheduler) worker() {
+	defer s.workers.Done()
+	for {
+		s.mu.Lock()
+		for len(s.queue) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if s.closed {
+			s.mu.Unlock()
+			return
+		}
+		path := s.queue[0]
+		s.queue = s.queue[1:]
+		j := s.jobs[path]
+		j.State, j.Since = Running, time.Now()
+		s.mu.Unlock()
+
+		s.exclusive.RLock()
+		s.run(path)
+		s.exclusive.RUnlock()
+
+		s.mu.Lock()
+		delete(s.jobs, path)
+		again := j.Again && !s.closed
+		s.mu.Unlock()
+		if again {
+			// Changed mid-sync: start a fresh burst so the new state gets its own pass
+			s.Touch(path)
+		}
+	}
+}
+
+// Exclusive runs fn once no sync is running, and holds back new syncs until it returns.
+// Pulls use it so a download never races a local sync of the same tree.
+func (s *Scheduler) Exclusive(fn func()) {
+	s.exclusive.Lock()
+	defer s.exclusive.Unlock()
+	fn()
+}
+
+// Jobs returns a snapshot of every path the scheduler knows about: running first, then queued
+// in the order they will run, then those still debouncing
+func (s *Scheduler) Jobs() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	position := make(map[string]int, len(s.queue))
+	for i, p := range s.queue {
+		position[p] = i
+	}
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, j.Job)
+	}
+	sort.Slice(jobs, func(a, b int) bool {
+		if jobs[a].State != jobs[b].State {
+			return jobs[a].State > jobs[b].State
+		}
+		if jobs[a].State == Queued {
+			return position[jobs[a].Path] < position[jobs[b].Path]
+		}
+		return jobs[a].Since.Before(jobs[b].Since)
+	})
+	return jobs
+}
+
+// Close discards pending work and waits for running syncs to finish
+func (s *Scheduler) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	for p, j := range s.jobs {
+		if j.timer != nil {
+			j.timer.Stop()
+		}
+		if j.State != Running {
+			delete(s.jobs, p)
+		}
+	}
+	s.queue = nil
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	s.workers.Wait()
+}
@@ -0,0 +1,244 @@
+package scheduler
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder counts runs per path and lets a test block them
+type recorder struct {
+	mu    sync.Mutex
+	runs  map[string][]time.Time
+	ran   chan string
+	block map[string]chan struct{} // closed to let a blocked run finish
+}
+
+func newRecorder() *recorder {
+	return &recorder{runs: map[string][]time.Time{}, ran: make(chan string, 100), block: map[string]chan struct{}{}}
+}
+
+func (r *recorder) run(path string) {
+	r.mu.Lock()
+	r.runs[path] = append(r.runs[path], time.Now())
+	gate := r.block[path]
+	r.mu.Unlock()
+	r.ran <- path
+	if gate != nil {
+		<-gate
+	}
+}
+
+func (r *recorder) count(path string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.runs[path])
+}
+
+// hold makes the next runs of path block until the returned function is called
+func (r *recorder) hold(path string) (release func()) {
+	gate := make(chan struct{})
+	r.mu.Lock()
+	r.block[path] = gate
+	r.mu.Unlock()
+	return func() {
+		r.mu.Lock()
+		delete(r.block, path)
+		r.mu.Unlock()
+		close(gate)
+	}
+}
+
+func (r *recorder) waitRun(t *testing.T, want string) {
+	t.Helper()
+	select {
+	case path := <-r.ran:
+		if path != want {
+			t.Fatalf("ran %s, want %s", path, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%s never ran", want)
+	}
+}
+
+func (r *recorder) noRun(t *testing.T, within time.Duration) {
+	t.Helper()
+	select {
+	case path := <-r.ran:
+		t.Fatalf("%s ran unexpectedly", path)
+	case <-time.After(within):
+	}
+}
+
+func tempFile(t *testing.T, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDebounceCoalescesBursts(t *testing.T) {
+	rec := newRecorder()
+	s := New(rec.run, Options{Debounce: 50 * time.Millisecond})
+	defer s.Close()
+	path := tempFile(t, "a")
+
+	for i := 0; i < 10; i++ {
+		s.Touch(path)
+		time.Sleep(5 * time.Millisecond)
+	}
+	rec.waitRun(t, path)
+	rec.noRun(t, 200*time.Millisecond)
+	if n := rec.count(path); n != 1 {
+		t.Fatalf("a burst of 10 events ran %d syncs, want 1", n)
+	}
+}
+
+func TestFileStillBeingWrittenIsDeferred(t *testing.T) {
+	rec := newRecorder()
+	s := New(rec.run, Options{Debounce: 60 * time.Millisecond, MaxWait: 10 * time.Second})
+	defer s.Close()
+	path := tempFile(t, "growing")
+
+	// One event, then the file keeps growing without further events: only its size and mtime
+	// tell the scheduler it is not done yet
+	s.Touch(path)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 15; i++ {
+		f.Write([]byte("more"))
+		time.Sleep(20 * time.Millisecond)
+	}
+	f.Close()
+	stopped := time.Now()
+
+	rec.waitRun(t, path)
+	rec.mu.Lock()
+	first := rec.runs[path][0]
+	rec.mu.Unlock()
+	if first.Before(stopped) {
+		t.Fatalf("synced %s before the writes stopped", stopped.Sub(first))
+	}
+}
+
+func TestMaxWaitBoundsAFileThatNeverSettles(t *testing.T) {
+	rec := newRecorder()
+	s := New(rec.run, Options{Debounce: 50 * time.Millisecond, MaxWait: 200 * time.Millisecond})
+	defer s.Close()
+	path := tempFile(t, "log")
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(10 * time.Millisecond):
+				f.Write([]byte("line\n"))
+			}
+		}
+	}()
+
+	s.Touch(path)
+	rec.waitRun(t, path)
+}
+
+func TestTouchDuringSyncRunsAgain(t *testing.T) {
+	rec := newRecorder()
+	s := New(rec.run, Options{Debounce: 20 * time.Millisecond})
+	defer s.Close()
+	path := tempFile(t, "b")
+
+	release := rec.hold(path)
+	s.Touch(path)
+	rec.waitRun(t, path)
+
+	s.Touch(path) // changed while its sync is running
+	if jobs := s.Jobs(); len(jobs) != 1 || jobs[0].State != Running || !jobs[0].Again {
+		t.Fatalf("jobs during the sync = %+v, want one running job marked Again", jobs)
+	}
+	release()
+
+	rec.waitRun(t, path)
+	if n := rec.count(path); n != 2 {
+		t.Fatalf("ran %d syncs, want 2", n)
+	}
+}
+
+func TestExclusiveWaitsForRunningSyncsAndHoldsNewOnes(t *testing.T) {
+	rec := newRecorder()
+	s := New(rec.run, Options{Debounce: 20 * time.Millisecond, Workers: 2})
+	defer s.Close()
+	a, b := tempFile(t, "a"), tempFile(t, "b")
+
+	releaseA := rec.hold(a)
+	s.Touch(a)
+	rec.waitRun(t, a)
+
+	entered := make(chan struct{})
+	leave := make(chan struct{})
+	exclusiveDone := make(chan struct{})
+	go func() {
+		s.Exclusive(func() {
+			close(entered)
+			<-leave
+		})
+		close(exclusiveDone)
+	}()
+
+	select {
+	case <-entered:
+		t.Fatal("Exclusive ran while a sync was still running")
+	case <-time.After(100 * time.Millisecond):
+	}
+	releaseA()
+	select {
+	case <-entered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Exclusive never ran after the sync finished")
+	}
+
+	// While it holds the scheduler, new syncs wait even with a worker free
+	s.Touch(b)
+	rec.noRun(t, 150*time.Millisecond)
+	close(leave)
+	<-exclusiveDone
+	rec.waitRun(t, b)
+}
+
+func TestCloseStopsEveryGoroutine(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	rec := newRecorder()
+	s := New(rec.run, Options{Debounce: time.Hour, Workers: 8})
+	dir := t.TempDir()
+	for i := 0; i < 20; i++ {
+		s.Touch(filepath.Join(dir, string(rune('a'+i)))) // left debouncing
+	}
+	s.Close()
+	s.Touch(filepath.Join(dir, "late")) // ignored once closed
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines running after Close, %d before New", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if jobs := s.Jobs(); len(jobs) != 0 {
+		t.Errorf("%d jobs left after Close", len(jobs))
+	}
+}
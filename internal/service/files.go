@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"delta-sync/delta-sync-pb/pkg/pb"
+	"delta-sync/internal/auth"
+	"delta-sync/internal/codec"
+	"delta-sync/internal/store"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Server) DownloadFile(in *pb.FileRequest, stream pb.DeltaSync_DownloadFileServer) error {
+	fmt.Printf("📂 Reconstruction request for file: %s (version %d)\n", in.FileName, in.Version)
+
+	// 1. Get the recipe (current or historical) from Neon
+	recipe, err := s.remoteDB.GetFileRecipe(auth.User(stream.Context()), in.FileName, int(in.Version))
+	if err == pgx.ErrNoRows {
+		return status.Errorf(codes.NotFound, "file %q (version %d) not found", in.FileName, in.Version)
+	}
+	if err != nil {
+		log.Printf("❌ Error fetching recipe from Neon: %v", err)
+		return err
+	}
+
+	for _, ref := range recipe.Chunks {
+		if !ref.Present {
+			return status.Errorf(codes.NotFound, "chunk %s of %s was never uploaded", ref.Hash, in.FileName)
+		}
+
+		// 2. Fetch binary data from the chunk store and undo at-rest compression
+		stored, err := s.chunkStore.Get(stream.Context(), ref.Hash)
+		if err != nil {
+			log.Printf("❌ Error fetching chunk %s: %v", ref.Hash, err)
+			return err
+		}
+		chunkData, err := codec.Decode(pb.Codec(ref.Codec), stored)
+		if err != nil {
+			log.Printf("❌ Error decompressing chunk %s: %v", ref.Hash, err)
+			return err
+		}
+
+		// 3. Stream the bytes back to the client
+		err = stream.Send(&pb.ChunkPayload{
+			Hash: ref.Hash,
+			Data: chunkData,
+			Size: int32(len(chunkData)),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("✅ Successfully sent %d chunks for %s\n", len(recipe.Chunks), in.FileName)
+	return nil
+}
+
+// DeleteFile drops a file's recipe after the client reports it was removed or renamed
+func (s *Server) DeleteFile(ctx context.Context, in *pb.FileRequest) (*pb.DeleteStatus, error) {
+	fmt.Printf("🗑️  Delete request for file: %s\n", in.FileName)
+
+	found, err := s.remoteDB.DeleteFileRecipe(auth.User(ctx), in.FileName)
+	if err != nil {
+		log.Printf("❌ Error deleting recipe: %v", err)
+		return nil, err
+	}
+	if !found {
+		return &pb.DeleteStatus{Success: false, Message: "File not found in registry"}, nil
+	}
+	return &pb.DeleteStatus{Success: true, Message: "File removed from registry"}, nil
+}
+
+// ListFiles returns the caller's registry so clients can detect newer recipes to pull down
+func (s *Server) ListFiles(ctx context.Context, in *pb.ListFilesRequest) (*pb.FileList, error) {
+	recipes, err := s.remoteDB.GetAllRecipes(auth.User(ctx))
+	if err != nil {
+		log.Printf("❌ Error listing recipes: %v", err)
+		return nil, err
+	}
+
+	list := &pb.FileList{}
+	for _, r := range recipes {
+		list.Files = append(list.Files, &pb.FileInfo{
+			FileName:    r.Name,
+			ChunkHashes: r.Hashes,
+			UpdatedAt:   timestamppb.New(r.UpdatedAt),
+		})
+	}
+	return list, nil
+}
+
+// GetRecipe returns the ordered chunk hashes and sizes of a file, optionally at a past version
+func (s *Server) GetRecipe(ctx context.Context, in *pb.FileRequest) (*pb.Recipe, error) {
+	recipe, err := s.remoteDB.GetFileRecipe(auth.User(ctx), in.FileName, int(in.Version))
+	if err == pgx.ErrNoRows {
+		return nil, status.Errorf(codes.NotFound, "file %q (version %d) not found", in.FileName, in.Version)
+	}
+	if err != nil {
+		log.Printf("❌ Error fetching recipe from Neon: %v", err)
+		return nil, err
+	}
+
+	resp := &pb.Recipe{
+		FileName:  in.FileName,
+		UpdatedAt: timestamppb.New(recipe.UpdatedAt),
+		Version:   int32(recipe.Version),
+	}
+	for _, ref := range recipe.Chunks {
+		resp.Chunks = append(resp.Chunks, &pb.ChunkRef{Hash: ref.Hash, Size: int32(ref.Size)})
+	}
+	return resp, nil
+}
+
+// ListVersions returns the stored history of a file, newest first
+func (s *Server) ListVersions(ctx context.Context, in *pb.FileRequest) (*pb.VersionList, error) {
+	versions, err := s.remoteDB.ListVersions(auth.User(ctx), in.FileName)
+	if err != nil {
+		log.Printf("❌ Error listing versions: %v", err)
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, status.Errorf(codes.NotFound, "file %q has no history", in.FileName)
+	}
+
+	list := &pb.VersionList{FileName: in.FileName}
+	for _, v := range versions {
+		list.Versions = append(list.Versions, &pb.FileVersion{
+			Version:    int32(v.Version),
+			CreatedAt:  timestamppb.New(v.CreatedAt),
+			Size:       v.Size,
+			ChunkCount: int32(v.ChunkCount),
+		})
+	}
+	return list, nil
+}
+
+// FetchChunks streams back only the chunks the client asked for. Chunks stored with a codec the
+// client accepts are sent as-is; anything else is decompressed first.
+func (s *Server) FetchChunks(in *pb.ChunkRequest, stream pb.DeltaSync_FetchChunksServer) error {
+	refs, err := s.remoteDB.GetChunkRefs(in.Hashes)
+	if err != nil {
+		log.Printf("❌ Error looking up chunks: %v", err)
+		return err
+	}
+	// The chunk store is shared for dedup; only hand out chunks the caller's own files reference
+	owned, err := s.remoteDB.OwnedChunks(auth.User(stream.Context()), in.Hashes)
+	if err != nil {
+		log.Printf("❌ Error checking chunk ownership: %v", err)
+		return err
+	}
+
+	sent := make(map[string]bool, len(in.Hashes))
+	for _, hash := range in.Hashes {
+		if sent[hash] {
+			continue
+		}
+		sent[hash] = true
+
+		ref, ok := refs[hash]
+		if !ok || !owned[hash] {
+			return status.Errorf(codes.NotFound, "chunk %s not found", hash)
+		}
+		data, err := s.chunkStore.Get(stream.Context(), hash)
+		if err == store.ErrNotFound {
+			return status.Errorf(codes.NotFound, "chunk %s not found", hash)
+		}
+		if err != nil {
+			log.Printf("❌ Error fetching chunk %s: %v", hash, err)
+			return err
+		}
+
+		wire := pb.Codec(ref.Codec)
+		if !slices.Contains(in.AcceptedCodecs, wire) && wire != pb.Codec_CODEC_STORED {
+			if data, err = codec.Decode(wire, data); err != nil {
+				log.Printf("❌ Error decompressing chunk %s: %v", hash, err)
+				return err
+			}
+			wire = pb.Codec_CODEC_STORED
+		}
+
+		err = stream.Send(&pb.ChunkPayload{Hash: hash, Data: data, Size: int32(ref.Size), Codec: wire})
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("✅ Sent %d requested chunks\n", len(sent))
+	return nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"delta-sync/delta-sync-pb/pkg/pb"
+	"delta-sync/internal/auth"
+	"delta-sync/internal/codec"
+	"delta-sync/internal/db"
+	"delta-sync/internal/events"
+	"delta-sync/internal/gc"
+	"delta-sync/internal/store"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server implements the DeltaSync gRPC service; cmd/server and cmd/combined both serve it
+type Server struct {
+	pb.UnimplementedDeltaSyncServer
+	remoteDB   *db.RemoteDB
+	chunkStore store.ChunkStore // raw chunk bytes; metadata stays in remoteDB
+	storeCodec pb.Codec         // compression applied to chunks at rest
+	events     *events.Bus      // sync progress for dashboards, in-process or via WatchEvents
+}
+
+func New(remoteDB *db.RemoteDB, chunkStore store.ChunkStore, storeCodec pb.Codec, bus *events.Bus) *Server {
+	return &Server{remoteDB: remoteDB, chunkStore: chunkStore, storeCodec: storeCodec, events: bus}
+}
+
+// FromEnv builds the service from CHUNK_STORE (postgres, fs or s3) and CHUNK_CODEC
+// (zstd by default, gzip or stored)
+func FromEnv(remoteDB *db.RemoteDB, bus *events.Bus) (*Server, error) {
+	chunkStore, err := store.FromEnv(remoteDB.Pool)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chunk store configuration: %w", err)
+	}
+
+	storeCodec := pb.Codec_CODEC_ZSTD
+	if name := os.Getenv("CHUNK_CODEC"); name != "" {
+		if storeCodec, err = codec.Parse(name); err != nil {
+			return nil, fmt.Errorf("invalid CHUNK_CODEC: %w", err)
+		}
+	}
+	fmt.Printf("📦 Chunk store: %T (codec %s)\n", chunkStore, storeCodec)
+
+	return New(remoteDB, chunkStore, storeCodec, bus), nil
+}
+
+// GRPCServer returns a gRPC server exposing the service, guarded by the authenticator when one
+// is configured, plus reflection for tools like grpcurl
+func (s *Server) GRPCServer(authenticator *auth.Authenticator) *grpc.Server {
+	// Long-lived clients ping idle connections every 30s; by default that would get them cut off
+	opts := []grpc.ServerOption{
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{MinTime: 20 * time.Second, PermitWithoutStream: true}),
+	}
+	if authenticator != nil {
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(authenticator.UnaryInterceptor()),
+			grpc.ChainStreamInterceptor(authenticator.StreamInterceptor()))
+		fmt.Println("🔐 Token authentication enabled")
+	} else {
+		fmt.Println("⚠️  Authentication disabled: set DELTASYNC_API_KEYS or DELTASYNC_JWT_SECRET")
+	}
+
+	grpcServer := grpc.NewServer(opts...)
+	pb.RegisterDeltaSyncServer(grpcServer, s)
+	reflection.Register(grpcServer)
+	return grpcServer
+}
+
+// StartGarbageCollector schedules GC passes configured through GC_* environment variables,
+// e.g. GC_INTERVAL=24h; it does nothing when GC_INTERVAL is unset
+func (s *Server) StartGarbageCollector() error {
+	interval := os.Getenv("GC_INTERVAL")
+	if interval == "" {
+		return nil
+	}
+	every, err := time.ParseDuration(interval)
+	if err != nil {
+		return fmt.Errorf("invalid GC_INTERVAL %q: %w", interval, err)
+	}
+
+	opts := gc.DefaultOptions
+	opts.DryRun = os.Getenv("GC_DRY_RUN") == "true"
+	if keep := os.Getenv("GC_KEEP_VERSIONS"); keep != "" {
+		if opts.KeepVersions, err = strconv.Atoi(keep); err != nil {
+			return fmt.Errorf("invalid GC_KEEP_VERSIONS %q: %w", keep, err)
+		}
+	}
+
+	fmt.Printf("🧹 Garbage collection scheduled every %s (dry run: %v)\n", every, opts.DryRun)
+	go gc.Schedule(s.remoteDB, s.chunkStore, every, opts)
+	return nil
+}
+
+// WatchEvents streams the caller's own sync events until the client disconnects
+func (s *Server) WatchEvents(in *pb.WatchEventsRequest, stream pb.DeltaSync_WatchEventsServer) error {
+	sub := s.events.Subscribe(events.ForOwner(auth.User(stream.Context())))
+	defer sub.Close()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case e := <-sub.C:
+			if err := stream.Send(events.ToProto(e)); err != nil {
+				return err
+			}
+		}
+	}
+}
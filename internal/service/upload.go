@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"delta-sync/delta-sync-pb/pkg/pb"
+	"delta-sync/internal/auth"
+	"delta-sync/internal/codec"
+	"delta-sync/internal/events"
+	"delta-sync/internal/store"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetMissingChunks compares the client hashes against the server's state
+func (s *Server) GetMissingChunks(ctx context.Context, in *pb.FileSignature) (*pb.MissingChunksResponse, error) {
+	fmt.Printf("Checking sync status for file: %s\n", in.FileId)
+
+	// The recipe is only published by CommitFile, once the upload below has finished
+	missingHashes, err := s.remoteDB.GetMissingChunks(auth.User(ctx), in.ChunkHashes)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return nil, err
+	}
+
+	fmt.Printf("Status: %d total chunks, %d missing from server. \n",
+		len(in.ChunkHashes), len(missingHashes))
+
+	resp := &pb.MissingChunksResponse{
+		MissingHashes: missingHashes,
+		Codec:         codec.Negotiate(in.AcceptedCodecs),
+	}
+	// Open an upload session so an interrupted stream can pick up where it stopped
+	if len(missingHashes) > 0 {
+		resp.SessionId, err = s.remoteDB.CreateUploadSession(auth.User(ctx), in.FileId, missingHashes)
+		if err != nil {
+			log.Printf("Error creating upload session: %v", err)
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// GetUploadSession tells a resuming client which chunks of its session the server still lacks
+func (s *Server) GetUploadSession(ctx context.Context, in *pb.UploadSessionRequest) (*pb.UploadSessionStatus, error) {
+	session, err := s.remoteDB.GetUploadSession(auth.User(ctx), in.SessionId)
+	if err == pgx.ErrNoRows {
+		return nil, status.Errorf(codes.NotFound, "upload session %s not found or expired", in.SessionId)
+	}
+	if err != nil {
+		log.Printf("Error loading upload session: %v", err)
+		return nil, err
+	}
+
+	pending, err := s.remoteDB.GetMissingChunks(session.Owner, session.Hashes)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return nil, err
+	}
+	fmt.Printf("🔁 Resuming upload of %s: %d of %d chunks still pending\n",
+		session.FileName, len(pending), len(session.Hashes))
+
+	return &pb.UploadSessionStatus{
+		SessionId:     session.ID,
+		FileId:        session.FileName,
+		PendingHashes: pending,
+		Committed:     int32(len(session.Hashes) - len(pending)),
+	}, nil
+}
+
+// CommitFile publishes a file's recipe once all of its chunks are stored. A refused commit
+// changes nothing, so a half-finished upload never produces a recipe DownloadFile can't serve.
+func (s *Server) CommitFile(ctx context.Context, in *pb.FileSignature) (*pb.CommitStatus, error) {
+	version, missing, err := s.remoteDB.CommitFileRecipe(auth.User(ctx), in.FileId, in.ChunkHashes, in.FileSize)
+	if err != nil {
+		log.Printf("Error committing recipe: %v", err)
+		return nil, err
+	}
+	if len(missing) > 0 {
+		fmt.Printf("⏳ Commit of %s refused: %d chunks still missing\n", in.FileId, len(missing))
+		return &pb.CommitStatus{
+			Success:       false,
+			Message:       fmt.Sprintf("%d chunks have not been uploaded", len(missing)),
+			MissingHashes: missing,
+		}, nil
+	}
+
+	fmt.Printf("📌 Committed %s as version %d\n", in.FileId, version)
+	return &pb.CommitStatus{Success: true, Message: "File committed", Version: int32(version)}, nil
+}
+
+// UploadChunks stores the chunks announced by the stream's leading UploadHeader
+func (s *Server) UploadChunks(stream pb.DeltaSync_UploadChunksServer) error {
+	first, err := stream.Recv()
+	if err != nil && err != io.EOF {
+		log.Printf("Error receiving upload header: %v", err)
+		return err
+	}
+	header := first.GetHeader()
+	if header == nil {
+		return status.Error(codes.InvalidArgument, "upload must start with a header")
+	}
+
+	// Chunks sent as part of an upload session must be ones the session asked for
+	owner := auth.User(stream.Context())
+	var sessionHashes map[string]bool
+	if header.SessionId != "" {
+		session, err := s.remoteDB.GetUploadSession(owner, header.SessionId)
+		if err == pgx.ErrNoRows {
+			return status.Errorf(codes.NotFound, "upload session %s not found or expired", header.SessionId)
+		}
+		if err != nil {
+			log.Printf("Error loading upload session: %v", err)
+			return err
+		}
+		sessionHashes = make(map[string]bool, len(session.Hashes))
+		for _, hash := range session.Hashes {
+			sessionHashes[hash] = true
+		}
+	}
+
+	fmt.Printf("📥 Receiving %s: %d chunks, %d bytes\n", header.FileId, header.ChunkCount, header.TotalBytes)
+	tracker := newUploadTracker(s.events, owner, header)
+	tracker.start()
+
+	if err := s.receiveChunks(stream, sessionHashes, tracker); err != nil {
+		tracker.fail(err)
+		return err
+	}
+	tracker.complete()
+	return stream.SendAndClose(&pb.UploadStatus{
+		Success:      true,
+		Message:      "All chunks received successfully!",
+		ChunksStored: int32(tracker.chunksDone),
+	})
+}
+
+// receiveChunks verifies and stores every chunk that follows the header until the client closes the stream
+func (s *Server) receiveChunks(stream pb.DeltaSync_UploadChunksServer, sessionHashes map[string]bool, tracker *uploadTracker) error {
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			log.Printf("Error receiving chunk: %v", err)
+			return err
+		}
+
+		chunk := msg.GetChunk()
+		if chunk == nil {
+			return status.Error(codes.InvalidArgument, "an upload carries a single header")
+		}
+		if sessionHashes != nil && !sessionHashes[chunk.Hash] {
+			return rejectChunk(chunk.Hash, tracker.chunksDone, errors.New("chunk is not part of the upload session"))
+		}
+
+		// Never trust the client's hash: a wrong one would poison dedup for every user
+		raw, err := codec.Decode(chunk.Codec, chunk.Data)
+		if err == nil {
+			err = verifyChunk(chunk.Hash, raw, chunk.Size)
+		}
+		if err != nil {
+			log.Printf("❌ Rejected chunk %s: %v", chunk.Hash, err)
+			return rejectChunk(chunk.Hash, tracker.chunksDone, err)
+		}
+
+		// Re-encode with the server's codec: the wire codec is the client's choice, while at-rest
+		// bytes must be identical no matter which client uploaded the chunk
+		atRest, stored, err := codec.Encode(s.storeCodec, raw)
+		if err != nil {
+			log.Printf("Error compressing chunk: %v", err)
+			return err
+		}
+
+		// Store the bytes first; the metadata row is what makes the chunk visible to GetMissingChunks
+		ctx := stream.Context()
+		err = s.remoteDB.StoreChunk(ctx, tracker.owner, chunk.Hash, len(raw), int32(atRest), len(stored), func(tx pgx.Tx) error {
+			return store.InTx(s.chunkStore, tx).Put(ctx, chunk.Hash, stored)
+		})
+		if err != nil {
+			log.Printf("Error storing chunk: %v", err)
+			return err
+		}
+
+		progress := tracker.stored(chunk.Hash, len(raw))
+		fmt.Printf("Stored chunk: %s (%d bytes, %d on the wire, %d at rest) - %.2f%%\n",
+			chunk.Hash, len(raw), len(chunk.Data), len(stored), progress.Percent())
+	}
+}
+
+// verifyChunk recomputes the SHA-256 of the decompressed payload and compares it with the claimed hash
+func verifyChunk(hash string, raw []byte, size int32) error {
+	sum := sha256.Sum256(raw)
+	if hex.EncodeToString(sum[:]) != hash {
+		return fmt.Errorf("data does not match hash %s", hash)
+	}
+	if size != 0 && int(size) != len(raw) {
+		return fmt.Errorf("declared size %d but received %d bytes", size, len(raw))
+	}
+	return nil
+}
+
+// rejectChunk builds an InvalidArgument status carrying an UploadStatus that names the bad chunk
+func rejectChunk(hash string, stored int, cause error) error {
+	st := status.New(codes.InvalidArgument, cause.Error())
+	detailed, err := st.WithDetails(&pb.UploadStatus{
+		Success:      false,
+		Message:      cause.Error(),
+		FailedHash:   hash,
+		ChunksStored: int32(stored),
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// uploadTracker keeps the running totals of one UploadChunks stream and publishes them as events
+type uploadTracker struct {
+	bus         *events.Bus
+	owner, file string
+	chunksTotal int
+	bytesTotal  int64
+	chunksDone  int
+	bytesDone   int64
+	started     time.Time
+}
+
+func newUploadTracker(bus *events.Bus, owner string, header *pb.UploadHeader) *uploadTracker {
+	return &uploadTracker{
+		bus:         bus,
+		owner:       owner,
+		file:        header.FileId,
+		chunksTotal: int(header.ChunkCount),
+		bytesTotal:  header.TotalBytes,
+		started:     time.Now(),
+	}
+}
+
+func (t *uploadTracker) meta() events.Meta {
+	return events.Meta{Owner: t.owner, File: t.file, At: time.Now()}
+}
+
+func (t *uploadTracker) start() {
+	t.bus.Publish(events.SyncStarted{Meta: t.meta(), Chunks: t.chunksTotal, Bytes: t.bytesTotal})
+}
+
+// stored accounts for one committed chunk of n uncompressed bytes and publishes rate and ETA
+func (t *uploadTracker) stored(hash string, n int) events.ChunkStored {
+	t.chunksDone++
+	t.bytesDone += int64(n)
+
+	e := events.ChunkStored{
+		Meta:        t.meta(),
+		Hash:        hash,
+		Size:        n,
+		ChunksDone:  t.chunksDone,
+		ChunksTotal: t.chunksTotal,
+		BytesDone:   t.bytesDone,
+		BytesTotal:  t.bytesTotal,
+	}
+	if elapsed := time.Since(t.started).Seconds(); elapsed > 0 {
+		e.BytesPerSecond = float64(t.bytesDone) / elapsed
+	}
+	if e.BytesPerSecond > 0 {
+		e.ETA = time.Duration(float64(max(t.bytesTotal-t.bytesDone, 0)) / e.BytesPerSecond * float64(time.Second))
+	}
+	t.bus.Publish(e)
+	return e
+}
+
+func (t *uploadTracker) complete() {
+	t.bus.Publish(events.SyncCompleted{
+		Meta:     t.meta(),
+		Chunks:   t.chunksDone,
+		Bytes:    t.bytesDone,
+		Duration: time.Since(t.started),
+	})
+}
+
+func (t *uploadTracker) fail(err error) {
+	t.bus.Publish(events.SyncFailed{Meta: t.meta(), Err: err.Error(), ChunksDone: t.chunksDone})
+}
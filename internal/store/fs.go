@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// FSStore keeps chunks in a content-addressed directory tree: <root>/ab/cd/abcd...
+type FSStore struct {
+	root string
+}
+
+// NewFSStore creates the root directory if needed
+func NewFSStore(root string) (*FSStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &FSStore{root: root}, nil
+}
+
+// path fans chunks out over two directory levels to keep directories small
+func (s *FSStore) path(hash string) (string, error) {
+	if err := validHash(hash); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, hash[0:2], hash[2:4], hash), nil
+}
+
+func (s *FSStore) Has(ctx context.Context, hash string) (bool, error) {
+	_, err := s.Stat(ctx, hash)
+	if err == ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *FSStore) Put(ctx context.Context, hash string, data []byte) error {
+	path, err := s.path(hash)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temp file and rename so readers never see a partial chunk
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".put-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *FSStore) Get(ctx context.Context, hash string) ([]byte, error) {
+	path, err := s.path(hash)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (s *FSStore) Delete(ctx context.Context, hash string) error {
+	path, err := s.path(hash)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (s *FSStore) Stat(ctx context.Context, hash string) (int64, error) {
+	path, err := s.path(hash)
+	if err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
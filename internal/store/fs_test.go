@@ -0,0 +1,86 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testHash = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+func TestFSStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	s, err := NewFSStore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Get(ctx, testHash); err != ErrNotFound {
+		t.Fatalf("Get of a missing chunk: got %v, want ErrNotFound", err)
+	}
+	if err := s.Put(ctx, testHash, []byte("first")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "9f", "86", testHash)); err != nil {
+		t.Fatalf("chunk not stored under its fan-out path: %v", err)
+	}
+
+	// A second Put replaces the bytes, e.g. after the server switched codecs
+	if err := s.Put(ctx, testHash, []byte("second")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := s.Get(ctx, testHash)
+	if err != nil || !bytes.Equal(data, []byte("second")) {
+		t.Fatalf("Get after overwrite = %q, %v", data, err)
+	}
+	if ok, err := s.Has(ctx, testHash); !ok || err != nil {
+		t.Fatalf("Has = %v, %v", ok, err)
+	}
+	if size, err := s.Stat(ctx, testHash); size != int64(len("second")) || err != nil {
+		t.Fatalf("Stat = %d, %v", size, err)
+	}
+
+	if err := s.Delete(ctx, testHash); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, testHash); err != ErrNotFound {
+		t.Fatalf("Get after Delete: got %v, want ErrNotFound", err)
+	}
+	if ok, err := s.Has(ctx, testHash); ok || err != nil {
+		t.Fatalf("Has after Delete = %v, %v", ok, err)
+	}
+	if _, err := s.Stat(ctx, testHash); err != ErrNotFound {
+		t.Fatalf("Stat after Delete: got %v, want ErrNotFound", err)
+	}
+	if err := s.Delete(ctx, testHash); err != nil {
+		t.Fatalf("deleting a missing chunk: %v", err)
+	}
+
+	// No temp files are left behind
+	var leftovers []string
+	filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err == nil && strings.HasPrefix(d.Name(), ".put-") {
+			leftovers = append(leftovers, path)
+		}
+		return nil
+	})
+	if len(leftovers) > 0 {
+		t.Errorf("temp files left behind: %v", leftovers)
+	}
+}
+
+func TestFSStoreRejectsInvalidHashes(t *testing.T) {
+	s, err := NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, hash := range []string{"", "../../etc/passwd", strings.Repeat("z", 64), testHash[:63]} {
+		if err := s.Put(context.Background(), hash, []byte("x")); err == nil {
+			t.Errorf("Put accepted hash %q", hash)
+		}
+	}
+}
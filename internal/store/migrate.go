@@ -0,0 +1,106 @@
+package store
+
+import (
+	"context"
+	"errors"
+)
+
+// fallbackStore reads chunks its store lacks from where they were kept before, so switching
+// CHUNK_STORE on an existing database leaves every file restorable. Writes and deletes only go
+// to the new store; the old bytes go when garbage collection deletes their rows or Migrate
+// moves them.
+type fallbackStore struct {
+	ChunkStore
+	legacy ChunkStore
+}
+
+func (s *fallbackStore) Has(ctx context.Context, hash string) (bool, error) {
+	ok, err := s.ChunkStore.Has(ctx, hash)
+	if ok || err != nil {
+		return ok, err
+	}
+	return s.legacy.Has(ctx, hash)
+}
+
+func (s *fallbackStore) Get(ctx context.Context, hash string) ([]byte, error) {
+	data, err := s.ChunkStore.Get(ctx, hash)
+	if err == ErrNotFound {
+		return s.legacy.Get(ctx, hash)
+	}
+	return data, err
+}
+
+func (s *fallbackStore) Stat(ctx context.Context, hash string) (int64, error) {
+	size, err := s.ChunkStore.Stat(ctx, hash)
+	if err == ErrNotFound {
+		return s.legacy.Stat(ctx, hash)
+	}
+	return size, err
+}
+
+// MigrateReport summarizes a Migrate run
+type MigrateReport struct {
+	Chunks int   // chunks moved out of Postgres
+	Bytes  int64 // bytes they held
+}
+
+// Migrate moves the chunk bytes still held in the chunks table into the store FromEnv built,
+// batchSize rows at a time. A chunk's bytes are only cleared from Postgres once the new store
+// holds all of them, so an interrupted run can simply be repeated.
+func Migrate(ctx context.Context, cs ChunkStore, batchSize int) (*MigrateReport, error) {
+	fallback, ok := cs.(*fallbackStore)
+	if !ok {
+		return nil, errors.New("CHUNK_STORE keeps chunks in Postgres; set it to fs or s3 to migrate")
+	}
+	legacy, ok := fallback.legacy.(*PostgresStore)
+	if !ok {
+		return nil, errors.New("chunks were not kept in Postgres")
+	}
+
+	report := &MigrateReport{}
+	after := ""
+	for {
+		rows, err := legacy.pool.Query(ctx,
+			`SELECT hash FROM chunks WHERE data IS NOT NULL AND hash > $1 ORDER BY hash LIMIT $2`, after, batchSize)
+		if err != nil {
+			return report, err
+		}
+		var hashes []string
+		for rows.Next() {
+			var hash string
+			if err := rows.Scan(&hash); err != nil {
+				rows.Close()
+				return report, err
+			}
+			hashes = append(hashes, hash)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return report, err
+		}
+		if len(hashes) == 0 {
+			return report, nil
+		}
+		after = hashes[len(hashes)-1]
+
+		for _, hash := range hashes {
+			data, err := legacy.Get(ctx, hash)
+			if err == ErrNotFound {
+				continue // collected since the page was read
+			}
+			if err != nil {
+				return report, err
+			}
+			if size, err := fallback.ChunkStore.Stat(ctx, hash); err != nil || size != int64(len(data)) {
+				if err := fallback.ChunkStore.Put(ctx, hash, data); err != nil {
+					return report, err
+				}
+			}
+			if _, err := legacy.pool.Exec(ctx, `UPDATE chunks SET data = NULL WHERE hash = $1`, hash); err != nil {
+				return report, err
+			}
+			report.Chunks++
+			report.Bytes += int64(len(data))
+		}
+	}
+}
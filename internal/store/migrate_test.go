@@ -0,0 +1,61 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+// A store switched away from Postgres still serves chunks only the old store holds
+func TestFallbackReadsLegacyChunks(t *testing.T) {
+	ctx := context.Background()
+	primary, err := NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, err := NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fallbackStore{ChunkStore: primary, legacy: legacy}
+
+	if err := legacy.Put(ctx, testHash, []byte("old")); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := s.Get(ctx, testHash); err != nil || !bytes.Equal(data, []byte("old")) {
+		t.Fatalf("Get of a legacy chunk = %q, %v", data, err)
+	}
+	if ok, err := s.Has(ctx, testHash); !ok || err != nil {
+		t.Fatalf("Has of a legacy chunk = %v, %v", ok, err)
+	}
+	if size, err := s.Stat(ctx, testHash); size != 3 || err != nil {
+		t.Fatalf("Stat of a legacy chunk = %d, %v", size, err)
+	}
+
+	// Writes go to the new store, which then wins
+	if err := s.Put(ctx, testHash, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := primary.Get(ctx, testHash); err != nil {
+		t.Fatalf("Put did not reach the new store: %v", err)
+	}
+	if data, err := s.Get(ctx, testHash); err != nil || !bytes.Equal(data, []byte("new")) {
+		t.Fatalf("Get = %q, %v", data, err)
+	}
+
+	// A chunk neither store holds is still not found
+	missing := "0000000000000000000000000000000000000000000000000000000000000000"
+	if _, err := s.Get(ctx, missing); err != ErrNotFound {
+		t.Fatalf("Get of a missing chunk: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestMigrateRequiresAStoreOutsidePostgres(t *testing.T) {
+	fs, err := NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Migrate(context.Background(), fs, 10); err == nil {
+		t.Fatal("Migrate into a store without a Postgres fallback succeeded")
+	}
+}
@@ -0,0 +1,82 @@
+package store
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrNotFound is returned when a chunk is not held by the store
+var ErrNotFound = errors.New("chunk not found")
+
+// ChunkStore holds the raw bytes of chunks, addressed by their SHA-256 hash. Metadata (which
+// chunks exist, their sizes and the recipes using them) always stays in Postgres.
+type ChunkStore interface {
+	// Has reports whether the bytes for hash are stored
+	Has(ctx context.Context, hash string) (bool, error)
+	// Put stores data under hash, replacing whatever was stored under it before. The same chunk
+	// may be encoded differently by servers with different codecs, so old bytes must not be kept.
+	Put(ctx context.Context, hash string, data []byte) error
+	// Get returns the bytes stored under hash, or ErrNotFound
+	Get(ctx context.Context, hash string) ([]byte, error)
+	// Delete removes the bytes stored under hash; deleting a missing hash is not an error
+	Delete(ctx context.Context, hash string) error
+	// Stat returns the stored size of hash, or ErrNotFound
+	Stat(ctx context.Context, hash string) (int64, error)
+}
+
+// FromEnv builds the store selected by CHUNK_STORE (postgres, fs or s3). Postgres, the
+// default, keeps bytes in the chunks table next to the metadata. The other stores fall back to
+// the chunks table for chunks stored before the switch, until Migrate has moved them.
+func FromEnv(pool *pgxpool.Pool) (ChunkStore, error) {
+	kind := os.Getenv("CHUNK_STORE")
+	if kind == "" || kind == "postgres" {
+		return NewPostgresStore(pool), nil
+	}
+	primary, err := storeFromEnv(kind)
+	if err != nil {
+		return nil, err
+	}
+	return &fallbackStore{ChunkStore: primary, legacy: NewPostgresStore(pool)}, nil
+}
+
+// storeFromEnv builds one of the stores that live outside Postgres
+func storeFromEnv(kind string) (ChunkStore, error) {
+	switch kind {
+	case "fs":
+		dir := os.Getenv("CHUNK_STORE_DIR")
+		if dir == "" {
+			return nil, errors.New("CHUNK_STORE=fs requires CHUNK_STORE_DIR")
+		}
+		return NewFSStore(dir)
+
+	case "s3":
+		cfg := S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    os.Getenv("S3_REGION"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			Prefix:    os.Getenv("S3_PREFIX"),
+		}
+		return NewS3Store(cfg)
+
+	default:
+		return nil, fmt.Errorf("unknown CHUNK_STORE %q (want postgres, fs or s3)", kind)
+	}
+}
+
+// validHash guards the path-based stores against names that are not SHA-256 hex digests
+func validHash(hash string) error {
+	if len(hash) != 64 {
+		return fmt.Errorf("invalid chunk hash %q", hash)
+	}
+	if _, err := hex.DecodeString(hash); err != nil {
+		return fmt.Errorf("invalid chunk hash %q", hash)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package syncer
+
+import "fmt"
+
+// RejectedError reports a chunk the server refused because its data did not match its hash
+type RejectedError struct {
+	Hash   string
+	Stored int // chunks of the upload stored before the rejected one
+	Reason string
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("upload rejected at chunk %s after %d stored: %s", e.Hash, e.Stored, e.Reason)
+}
+
+// CommitError reports a recipe the server would not publish
+type CommitError struct {
+	Reason string
+}
+
+func (e *CommitError) Error() string {
+	return "commit refused: " + e.Reason
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package syncer
+
+import "os"
+
+// inodeOf is always 0 where the platform exposes no inode; size and mtime still apply
+func inodeOf(info os.FileInfo) uint64 {
+	return 0
+}
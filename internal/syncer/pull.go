@@ -0,0 +1,232 @@
+package syncer
+
+import (
+	"context"
+	"crypto/sha256"
+	"delta-sync/delta-sync-pb/pkg/pb"
+	"delta-sync/internal/codec"
+	"delta-sync/internal/db"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Restore rebuilds the current version of the server file name at dest; see Pull
+func (s *Syncer) Restore(name, dest string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	return s.Pull(ctx, name, 0, dest)
+}
+
+// Pull rebuilds version of the server file name (0 for the current one) at dest. It diffs the
+// server's recipe against the local chunk cache and downloads only the chunks that are not
+// already held locally. dest is replaced atomically; a current version is recorded as in sync,
+// while an older one is left for the next push to publish as a new version.
+func (s *Syncer) Pull(ctx context.Context, name string, version int, dest string) error {
+	defer s.trimCache()
+
+	// 1. Fetch the authoritative recipe
+	recipe, err := s.client.GetRecipe(ctx, &pb.FileRequest{FileName: name, Version: int32(version)})
+	if err != nil {
+		return err
+	}
+
+	// 2. Work out which chunks the cache cannot provide. Every chunk of the file stays pinned in
+	// the cache until it is written out, however far that takes the cache past its limit.
+	hashes := make([]string, len(recipe.Chunks))
+	for i, ref := range recipe.Chunks {
+		hashes[i] = ref.Hash
+	}
+	defer s.localDB.Pin(hashes)()
+
+	var missing []string
+	var reusedBytes, missingBytes int64
+	seen := make(map[string]bool)
+	for _, ref := range recipe.Chunks {
+		if seen[ref.Hash] {
+			continue
+		}
+		seen[ref.Hash] = true
+
+		ok, err := s.localDB.HasCachedChunk(ref.Hash)
+		if err != nil {
+			return err
+		}
+		if ok {
+			reusedBytes += int64(ref.Size)
+		} else {
+			missing = append(missing, ref.Hash)
+			missingBytes += int64(ref.Size)
+		}
+	}
+	s.cfg.Logf("📊 %d/%d chunks reused locally (%d bytes), downloading %d (%d bytes)\n",
+		len(seen)-len(missing), len(seen), reusedBytes, len(missing), missingBytes)
+
+	// 3. Download only the difference straight into the cache
+	if err := s.fetchChunks(ctx, name, missing, missingBytes); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+
+	// 4. Write next to the target under a hidden name and rename it into place, so neither
+	// the watcher nor an editor ever sees a half-written file
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".deltasync-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	indexed, complete, err := s.writeFromCache(hashes, tmp)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	if !complete {
+		tmp.Close()
+		return fmt.Errorf("server did not return every missing chunk")
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return err
+	}
+
+	// Record the pulled version so the watcher's follow-up event is recognised as already in sync
+	if version != 0 {
+		return nil
+	}
+	info, err := os.Stat(dest)
+	if err != nil {
+		return err
+	}
+	return s.localDB.SaveFileIndex(dest, db.FileEntry{
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Inode:   inodeOf(info),
+		Chunks:  indexed,
+	})
+}
+
+// PullChanges downloads into Root every registry file whose content on the server differs from
+// what was last synced. Only chunk hashes are compared, never the server's clock against ours.
+// Local edits that have not been pushed yet always win.
+func (s *Syncer) PullChanges(ctx context.Context) error {
+	if s.cfg.Root == "" {
+		return fmt.Errorf("pulling changes needs a root directory")
+	}
+
+	listCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	list, err := s.client.ListFiles(listCtx, &pb.ListFilesRequest{})
+	if err != nil {
+		return fmt.Errorf("pull failed: %w", err)
+	}
+
+	for _, f := range list.Files {
+		localPath, ok := s.LocalPath(f.FileName)
+		if !ok || s.Ignored(localPath) {
+			continue
+		}
+
+		entry, found, err := s.localDB.GetFileIndex(localPath)
+		if err != nil {
+			s.cfg.Logf("Index lookup failed: %v\n", err)
+			continue
+		}
+
+		info, statErr := os.Stat(localPath)
+		switch {
+		case found && slices.Equal(entry.Hashes(), f.ChunkHashes):
+			continue // already identical
+		case found && statErr == nil && !entry.Matches(info.Size(), info.ModTime(), inodeOf(info)):
+			continue // local edits that have not been pushed yet win
+		case !found && statErr == nil:
+			continue // an unindexed local file is pushed by the watcher, never overwritten
+		}
+
+		s.cfg.Logf("📥 Pulling newer version of %s...\n", f.FileName)
+		pullCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		err = s.Pull(pullCtx, f.FileName, 0, localPath)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			s.cfg.Logf("Pull of %s failed: %v\n", f.FileName, err)
+			continue
+		}
+		s.cfg.Logf("🎉 %s reconstructed locally!\n", f.FileName)
+	}
+	return nil
+}
+
+// fetchChunks downloads the given chunks of the server file name, verifies them and stores their
+// plaintext in the cache. totalBytes is only used for progress reports.
+func (s *Syncer) fetchChunks(ctx context.Context, name string, hashes []string, totalBytes int64) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+	stream, err := s.client.FetchChunks(ctx, &pb.ChunkRequest{Hashes: hashes, AcceptedCodecs: codec.Supported})
+	if err != nil {
+		return err
+	}
+	progress := Progress{File: name, Download: true, ChunksTotal: len(hashes), BytesTotal: totalBytes}
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		data, err := codec.Decode(chunk.Codec, chunk.Data)
+		if err != nil {
+			return fmt.Errorf("chunk %s: %w", chunk.Hash, err)
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != chunk.Hash {
+			return fmt.Errorf("chunk %s failed verification", chunk.Hash)
+		}
+		if s.sealer != nil {
+			if data, err = s.sealer.Open(data); err != nil {
+				return fmt.Errorf("chunk %s: %w", chunk.Hash, err)
+			}
+		}
+		if err := s.localDB.CacheChunk(chunk.Hash, data); err != nil {
+			return err
+		}
+		progress.ChunksDone++
+		progress.BytesDone += int64(chunk.Size)
+		s.report(progress)
+	}
+}
+
+// writeFromCache writes the file from locally cached chunks, reporting false if any chunk is missing.
+// It returns the recipe with plaintext hashes and sizes, so the next push can rechunk around edits.
+func (s *Syncer) writeFromCache(hashes []string, w io.Writer) ([]db.IndexedChunk, bool, error) {
+	indexed := make([]db.IndexedChunk, 0, len(hashes))
+	for _, hash := range hashes {
+		data, ok, err := s.localDB.CachedChunk(hash)
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			return nil, false, nil
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, false, err
+		}
+		sum := sha256.Sum256(data)
+		indexed = append(indexed, db.IndexedChunk{Hash: hash, ContentHash: hex.EncodeToString(sum[:]), Size: len(data)})
+	}
+	return indexed, true, nil
+}
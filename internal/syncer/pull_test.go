@@ -0,0 +1,75 @@
+package syncer
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// A file larger than the chunk cache must still restore: its chunks are pinned while it is
+// written out, even though fetching them trims the cache several times
+func TestPullLargerThanCache(t *testing.T) {
+	const limit = 1 << 20
+	s, fake := newTestSyncer(t, limit)
+	data := randomBytes(1, 40*64<<10)
+	fake.put("big.bin", data, 64<<10, time.Now())
+
+	dest := filepath.Join(s.cfg.Root, "big.bin")
+	if err := s.Pull(context.Background(), "big.bin", 0, dest); err != nil {
+		t.Fatalf("Pull: %v", err)
+	}
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("restored %d bytes that differ from the %d on the server", len(got), len(data))
+	}
+
+	// Once the file is written its chunks are ordinary cache entries again
+	_, size, err := s.localDB.CacheUsage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size > limit {
+		t.Errorf("cache holds %d bytes after the pull, above its %d byte limit", size, limit)
+	}
+}
+
+// A newer remote version is pulled even when the server's clock is behind ours, and never over
+// local edits that have not been pushed
+func TestPullChangesComparesContentNotClocks(t *testing.T) {
+	s, fake := newTestSyncer(t, 0)
+	ctx := context.Background()
+	path := filepath.Join(s.cfg.Root, "notes.txt")
+
+	fake.put("notes.txt", []byte("first version"), 4, time.Now())
+	if err := s.PullChanges(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "first version" {
+		t.Fatalf("after the first pull the file holds %q", got)
+	}
+
+	fake.put("notes.txt", []byte("second version"), 4, time.Now().Add(-time.Hour))
+	if err := s.PullChanges(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "second version" {
+		t.Fatalf("a version stamped before the last sync was skipped; the file holds %q", got)
+	}
+
+	if err := os.WriteFile(path, []byte("local edit, not pushed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fake.put("notes.txt", []byte("third version"), 4, time.Now())
+	if err := s.PullChanges(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "local edit, not pushed" {
+		t.Fatalf("a pull overwrote a local edit with %q", got)
+	}
+}
@@ -0,0 +1,379 @@
+package syncer
+
+import (
+	"context"
+	"crypto/sha256"
+	"delta-sync/delta-sync-pb/pkg/pb"
+	"delta-sync/internal/chunker"
+	"delta-sync/internal/codec"
+	"delta-sync/internal/db"
+	"delta-sync/internal/encrypt"
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxUploadAttempts bounds how often an interrupted upload is resumed before giving up until the next sync
+const maxUploadAttempts = 6
+
+// Result describes what a push did
+type Result struct {
+	FileID        string
+	Version       int  // version published; 0 when nothing changed
+	Unchanged     bool // the server already had exactly this content
+	Chunks        int  // chunks in the file
+	Uploaded      int  // chunks actually sent
+	UploadedBytes int64
+}
+
+// Sync pushes the local file at path under its FileID; see Push
+func (s *Syncer) Sync(path string) error {
+	_, err := s.Push(context.Background(), path, s.FileID(path))
+	return err
+}
+
+// Push uploads the local file at path as fileID, sending only the chunks the server lacks, and
+// publishes it as a new version
+func (s *Syncer) Push(ctx context.Context, path, fileID string) (Result, error) {
+	result := Result{FileID: fileID}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return result, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return result, err
+	}
+
+	// Size, mtime and inode exactly as last synced means the content is too; skip reading it
+	prior, found, err := s.localDB.GetFileIndex(path)
+	if err != nil {
+		s.cfg.Logf("Index lookup failed: %v\n", err)
+	}
+	if found && prior.Matches(info.Size(), info.ModTime(), inodeOf(info)) && s.FileID(path) == fileID {
+		s.cfg.Logf("✨ No changes since the last sync.\n")
+		result.Unchanged, result.Chunks = true, len(prior.Chunks)
+		return result, nil
+	}
+
+	// Stream the file once for its signature; bytes pass through the local cache, which is
+	// trimmed as it fills, and are never all held in memory, so file size does not matter
+	// With encryption on, the server only ever sees hashes of sealed chunks
+	priorChunks, known := s.priorBoundaries(prior)
+	var chunks []chunker.Chunk
+	var hashList []string
+	var indexed []db.IndexedChunk
+	var fileSize int64
+	scan := func(c chunker.Chunk) error {
+		// Chunks carried over from the last sync are already named and cached
+		remoteHash, ok := known[c.Hash]
+		if !ok {
+			remoteHash = s.remoteHashOf(c)
+			if err := s.localDB.CacheChunk(remoteHash, c.Data); err != nil {
+				return err
+			}
+		}
+		c.Data = nil
+		chunks = append(chunks, c)
+		hashList = append(hashList, remoteHash)
+		indexed = append(indexed, db.IndexedChunk{Hash: remoteHash, ContentHash: c.Hash, Size: c.Size})
+		fileSize += int64(c.Size)
+		return nil
+	}
+	if priorChunks != nil {
+		// Only the edited region is chunked afresh; the rest keeps its old boundaries
+		reused, err := s.cfg.Chunking.Rechunk(file, info.Size(), priorChunks, scan)
+		if err != nil {
+			return result, fmt.Errorf("analysis failed: %w", err)
+		}
+		s.cfg.Logf("♻️  Reused %d of %d chunk boundaries from the last sync\n", reused, len(chunks))
+	} else if err := s.cfg.Chunking.Scan(file, scan); err != nil {
+		return result, fmt.Errorf("analysis failed: %w", err)
+	}
+	result.Chunks = len(chunks)
+	// The cache now holds as much of this version as fits; evict whatever has gone unused longest
+	defer s.trimCache()
+
+	entry := db.FileEntry{Size: info.Size(), ModTime: info.ModTime(), Inode: inodeOf(info), Chunks: indexed}
+
+	// Files we just pulled (or already pushed) come back through the watcher; don't echo them
+	if found && slices.Equal(prior.Hashes(), hashList) && s.FileID(path) == fileID {
+		s.cfg.Logf("✨ No content changes since the last sync.\n")
+		s.localDB.SaveFileIndex(path, entry) // remember the new mtime so the next check is metadata-only
+		result.Unchanged = true
+		return result, nil
+	}
+
+	rpcCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	signature := &pb.FileSignature{
+		FileId:         fileID,
+		ChunkHashes:    hashList,
+		FileSize:       fileSize,
+		AcceptedCodecs: codec.Supported,
+	}
+	resp, err := s.client.GetMissingChunks(rpcCtx, signature)
+	if err != nil {
+		return result, fmt.Errorf("sync failed: %w", err)
+	}
+
+	byHash := make(map[string]chunker.Chunk, len(chunks))
+	for i, c := range chunks {
+		byHash[hashList[i]] = c
+	}
+
+	if len(resp.MissingHashes) > 0 {
+		s.cfg.Logf("📤 Syncing %d new/modified chunks...\n", len(resp.MissingHashes))
+
+		// A dropped stream resumes within the same session, re-sending only what never arrived
+		pending := resp.MissingHashes
+		for attempt := 1; len(pending) > 0; attempt++ {
+			err := s.uploadChunks(ctx, fileID, file, byHash, pending, resp.Codec, resp.SessionId, &result)
+			if err == nil {
+				break
+			}
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+			if !retryable(err) || resp.SessionId == "" || attempt == maxUploadAttempts {
+				return result, uploadError(err)
+			}
+
+			wait := uploadBackoff(attempt)
+			s.cfg.Logf("Upload interrupted (%v); resuming in %s\n", status.Convert(err).Message(), wait)
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return result, ctx.Err()
+			}
+
+			st, err := s.resumeSession(ctx, resp.SessionId)
+			if err != nil {
+				if retryable(err) {
+					continue // server still unreachable; the next attempt re-sends everything pending
+				}
+				return result, fmt.Errorf("could not resume upload: %w", err)
+			}
+			s.cfg.Logf("🔁 Resuming: %d chunks already on the server, %d to go\n", st.Committed, len(st.PendingHashes))
+			pending = st.PendingHashes
+		}
+	} else {
+		s.cfg.Logf("✨ Server already holds every chunk of this version.\n")
+	}
+
+	// Publish the version only now that its chunks are stored
+	commit, err := s.commitFile(ctx, signature)
+	if err == nil && !commit.Success && len(commit.MissingHashes) > 0 {
+		// A chunk counted as present earlier can be gone by now (e.g. collected as garbage); send it once more
+		s.cfg.Logf("📤 Re-sending %d chunks the server no longer has...\n", len(commit.MissingHashes))
+		if err = s.uploadChunks(ctx, fileID, file, byHash, commit.MissingHashes, resp.Codec, "", &result); err != nil {
+			return result, uploadError(err)
+		}
+		commit, err = s.commitFile(ctx, signature)
+	}
+	if err != nil {
+		return result, fmt.Errorf("commit failed: %w", err)
+	}
+	if !commit.Success {
+		return result, &CommitError{Reason: commit.Message}
+	}
+	s.cfg.Logf("✅ Delta-Sync Complete! (version %d)\n", commit.Version)
+	result.Version = int(commit.Version)
+
+	// Only remember the version once the server holds it, so a failed sync is retried next time.
+	// The index is keyed by path, so a push under another name must not claim the path is in sync.
+	if s.FileID(path) != fileID {
+		return result, nil
+	}
+	return result, s.localDB.SaveFileIndex(path, entry)
+}
+
+// Delete removes a deleted file, or every indexed file below a deleted directory, from the server
+func (s *Syncer) Delete(ctx context.Context, path string) error {
+	// The caller cannot always tell whether the missing path was a file or a directory,
+	// so the local index decides which registry entries have to go
+	paths, err := s.localDB.IndexedPathsUnder(path)
+	if err != nil {
+		return fmt.Errorf("index lookup failed: %w", err)
+	}
+	if _, found, err := s.localDB.GetFileIndex(path); err == nil && found {
+		paths = append(paths, path)
+	}
+	if len(paths) == 0 {
+		return nil // never synced, e.g. an editor's temp file
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	for _, p := range paths {
+		resp, err := s.client.DeleteFile(ctx, &pb.FileRequest{FileName: s.FileID(p)})
+		if err != nil {
+			return fmt.Errorf("delete failed for %s: %w", p, err)
+		}
+		if resp.Success {
+			s.cfg.Logf("🗑️  Removed from server: %s\n", p)
+		}
+		s.localDB.DeleteFileIndex(p)
+	}
+	return nil
+}
+
+// priorBoundaries turns the last synced recipe into chunks Rechunk can start from, plus a map from
+// plaintext hash to server name. It returns nil if the recipe predates sizes and plaintext hashes,
+// or was synced with encryption switched the other way, which renames every chunk.
+func (s *Syncer) priorBoundaries(prior db.FileEntry) ([]chunker.Chunk, map[string]string) {
+	if len(prior.Chunks) == 0 {
+		return nil, nil
+	}
+	chunks := make([]chunker.Chunk, len(prior.Chunks))
+	known := make(map[string]string, len(prior.Chunks))
+	var offset int64
+	for i, c := range prior.Chunks {
+		if c.ContentHash == "" || c.Size <= 0 || (c.Hash == c.ContentHash) != (s.sealer == nil) {
+			return nil, nil
+		}
+		chunks[i] = chunker.Chunk{Hash: c.ContentHash, Size: c.Size, Offset: offset}
+		known[c.ContentHash] = c.Hash
+		offset += int64(c.Size)
+	}
+	return chunks, known
+}
+
+// remoteHashOf is the name a chunk has on the server: its plain SHA-256, or the SHA-256 of the
+// sealed bytes when encryption is on, which is what the server verifies and dedups on
+func (s *Syncer) remoteHashOf(c chunker.Chunk) string {
+	if s.sealer == nil {
+		return c.Hash
+	}
+	sum := sha256.Sum256(s.sealer.Seal(c.Data))
+	return hex.EncodeToString(sum[:])
+}
+
+// commitFile asks the server to publish the recipe of a fully uploaded file
+func (s *Syncer) commitFile(ctx context.Context, signature *pb.FileSignature) (*pb.CommitStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	return s.client.CommitFile(ctx, signature)
+}
+
+// uploadChunks streams the given chunks in one UploadChunks call, re-reading each from the file by
+// offset, and counts what it sent into result
+func (s *Syncer) uploadChunks(ctx context.Context, fileID string, file *os.File, byHash map[string]chunker.Chunk, hashes []string, negotiated pb.Codec, sessionID string, result *Result) error {
+	stream, err := s.client.UploadChunks(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Announce what follows so the server can report byte-accurate progress
+	header := &pb.UploadHeader{FileId: fileID, SessionId: sessionID}
+	for _, hash := range hashes {
+		if c, ok := byHash[hash]; ok {
+			header.ChunkCount++
+			header.TotalBytes += int64(c.Size)
+			if s.sealer != nil {
+				header.TotalBytes += encrypt.Overhead
+			}
+		}
+	}
+	if err := stream.Send(&pb.UploadRequest{Payload: &pb.UploadRequest_Header{Header: header}}); err != nil {
+		_, err = stream.CloseAndRecv()
+		return err
+	}
+
+	progress := Progress{File: fileID, ChunksTotal: int(header.ChunkCount), BytesTotal: header.TotalBytes}
+	for _, hash := range hashes {
+		c, ok := byHash[hash]
+		if !ok {
+			continue
+		}
+		data, err := chunker.ReadChunk(file, c)
+		if err != nil {
+			stream.CloseSend()
+			return err
+		}
+		// Ciphertext does not compress, so sealed chunks skip straight to stored
+		wireCodec := negotiated
+		if s.sealer != nil {
+			data = s.sealer.Seal(data)
+			wireCodec = pb.Codec_CODEC_STORED
+		}
+
+		// Compress with the negotiated codec; incompressible chunks go out as stored
+		wire, payload, err := codec.Encode(wireCodec, data)
+		if err != nil {
+			stream.CloseSend()
+			return err
+		}
+		err = stream.Send(&pb.UploadRequest{Payload: &pb.UploadRequest_Chunk{Chunk: &pb.ChunkPayload{
+			Hash:  hash,
+			Data:  payload,
+			Size:  int32(len(data)),
+			Codec: wire,
+		}}})
+		if err != nil {
+			// The real status (e.g. a rejected chunk) is reported by CloseAndRecv below
+			break
+		}
+		result.Uploaded++
+		result.UploadedBytes += int64(len(data))
+		progress.ChunksDone++
+		progress.BytesDone += int64(len(data))
+		s.report(progress)
+	}
+	_, err = stream.CloseAndRecv()
+	return err
+}
+
+// resumeSession asks the server which chunks of an interrupted upload it is still missing
+func (s *Syncer) resumeSession(ctx context.Context, sessionID string) (*pb.UploadSessionStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	return s.client.GetUploadSession(ctx, &pb.UploadSessionRequest{SessionId: sessionID})
+}
+
+// retryable reports whether an upload failed in transit rather than being refused by the server.
+// Local errors (e.g. the file changing mid-upload) carry no gRPC status and are never retried.
+func retryable(err error) bool {
+	st, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	switch st.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted, codes.ResourceExhausted, codes.Internal, codes.Unknown:
+		return true
+	}
+	return false
+}
+
+// uploadBackoff is the wait before resume attempt n: 500ms doubling up to 30s, with jitter so many
+// clients cut off by the same outage don't reconnect in lockstep
+func uploadBackoff(attempt int) time.Duration {
+	wait := min(500*time.Millisecond<<(attempt-1), 30*time.Second)
+	return wait/2 + rand.N(wait/2)
+}
+
+// uploadError explains why an upload stopped for good
+func uploadError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		// The file changed under us; the watcher will trigger a fresh sync
+		return fmt.Errorf("upload aborted: %w", err)
+	}
+	// The server names the offending chunk when it rejects one
+	for _, detail := range st.Details() {
+		if us, ok := detail.(*pb.UploadStatus); ok && us.FailedHash != "" {
+			return &RejectedError{Hash: us.FailedHash, Stored: int(us.ChunksStored), Reason: us.Message}
+		}
+	}
+	return fmt.Errorf("upload failed: %w", err)
+}
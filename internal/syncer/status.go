@@ -0,0 +1,255 @@
+package syncer
+
+import (
+	"context"
+	"crypto/sha256"
+	"delta-sync/delta-sync-pb/pkg/pb"
+	"delta-sync/internal/encrypt"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+)
+
+// State is how a file under Root compares with the server
+type State string
+
+const (
+	InSync    State = "synced"    // unchanged on both sides since the last sync
+	Modified  State = "modified"  // changed locally since the last sync
+	Outdated  State = "outdated"  // changed on the server since the last sync
+	Conflict  State = "conflict"  // changed on both sides
+	Added     State = "added"     // only exists locally
+	Deleted   State = "deleted"   // synced before but gone locally
+	Remote    State = "remote"    // only exists on the server
+	Untracked State = "untracked" // exists on both sides but was never synced from here; Verify tells
+)
+
+// FileStatus is one line of Status
+type FileStatus struct {
+	Name  string // server name
+	Path  string // local path below Root
+	State State
+}
+
+// Status compares every file below Root with the server's registry. It only looks at file
+// metadata and the local index, so it is cheap but trusts that an unchanged mtime means
+// unchanged content.
+func (s *Syncer) Status(ctx context.Context) ([]FileStatus, error) {
+	if s.cfg.Root == "" {
+		return nil, fmt.Errorf("status needs a root directory")
+	}
+
+	list, err := s.client.ListFiles(ctx, &pb.ListFilesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	remote := make(map[string]*pb.FileInfo, len(list.Files))
+	for _, f := range list.Files {
+		remote[f.FileName] = f
+	}
+
+	var statuses []FileStatus
+	err = filepath.WalkDir(s.cfg.Root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != s.cfg.Root && s.Ignored(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entry, found, err := s.localDB.GetFileIndex(path)
+		if err != nil {
+			return err
+		}
+
+		name := s.FileID(path)
+		f, onServer := remote[name]
+		delete(remote, name)
+
+		st := FileStatus{Name: name, Path: path}
+		localChanged := found && !entry.Matches(info.Size(), info.ModTime(), inodeOf(info))
+		remoteChanged := found && onServer && !slices.Equal(entry.Hashes(), f.ChunkHashes)
+		switch {
+		case !onServer:
+			st.State = Added
+		case !found:
+			st.State = Untracked
+		case localChanged && remoteChanged:
+			st.State = Conflict
+		case localChanged:
+			st.State = Modified
+		case remoteChanged:
+			st.State = Outdated
+		default:
+			st.State = InSync
+		}
+		statuses = append(statuses, st)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Whatever is left only exists on the server
+	for name := range remote {
+		path, ok := s.LocalPath(name)
+		if !ok || s.Ignored(path) {
+			continue
+		}
+		st := FileStatus{Name: name, Path: path, State: Remote}
+		if _, found, err := s.localDB.GetFileIndex(path); err == nil && found {
+			st.State = Deleted
+		}
+		statuses = append(statuses, st)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses, nil
+}
+
+// MismatchError reports where a local file first differs from the server's copy
+type MismatchError struct {
+	Name   string
+	Offset int64
+	Reason string
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("%s differs from the server at byte %d: %s", e.Name, e.Offset, e.Reason)
+}
+
+// Verify checks that the local file at path holds exactly the current server version of name,
+// returning a *MismatchError if it does not. The file is hashed along the server's chunk
+// boundaries, so nothing is downloaded unless chunks are sealed and their plaintext is unknown.
+func (s *Syncer) Verify(ctx context.Context, path, name string) error {
+	recipe, err := s.client.GetRecipe(ctx, &pb.FileRequest{FileName: name})
+	if err != nil {
+		return err
+	}
+	expected, err := s.plaintextHashes(ctx, path, recipe)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var offset int64
+	var buf []byte
+	for i, ref := range recipe.Chunks {
+		size := int(ref.Size)
+		if s.sealer != nil {
+			size -= encrypt.Overhead
+		}
+		if size < 0 {
+			return fmt.Errorf("chunk %s has an invalid size %d", ref.Hash, ref.Size)
+		}
+		buf = slices.Grow(buf[:0], size)[:size]
+		n, err := io.ReadFull(file, buf)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return &MismatchError{Name: name, Offset: offset + int64(n), Reason: "local file is shorter"}
+		}
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(buf)
+		if hex.EncodeToString(sum[:]) != expected[i] {
+			return &MismatchError{Name: name, Offset: offset, Reason: "content differs"}
+		}
+		offset += int64(size)
+	}
+	if n, _ := file.Read(make([]byte, 1)); n > 0 {
+		return &MismatchError{Name: name, Offset: offset, Reason: "local file is longer"}
+	}
+	return nil
+}
+
+// plaintextHashes returns the hash of each recipe chunk's plaintext. Without encryption that is
+// the hash the server knows; sealed chunks are looked up in the local index and otherwise
+// opened, downloading any the cache lacks.
+func (s *Syncer) plaintextHashes(ctx context.Context, path string, recipe *pb.Recipe) ([]string, error) {
+	hashes := make([]string, len(recipe.Chunks))
+	if s.sealer == nil {
+		for i, ref := range recipe.Chunks {
+			hashes[i] = ref.Hash
+		}
+		return hashes, nil
+	}
+
+	known := make(map[string]string)
+	if entry, found, err := s.localDB.GetFileIndex(path); err == nil && found {
+		for _, c := range entry.Chunks {
+			known[c.Hash] = c.ContentHash
+		}
+	}
+	var unknown []*pb.ChunkRef
+	seen := make(map[string]bool)
+	for _, ref := range recipe.Chunks {
+		if _, ok := known[ref.Hash]; ok || seen[ref.Hash] {
+			continue
+		}
+		seen[ref.Hash] = true
+		unknown = append(unknown, ref)
+	}
+	// The chunks must survive until they are hashed below, even if they overflow the cache;
+	// the trim is deferred first so it only runs once they are unpinned
+	defer s.trimCache()
+	pinned := make([]string, len(unknown))
+	for i, ref := range unknown {
+		pinned[i] = ref.Hash
+	}
+	defer s.localDB.Pin(pinned)()
+
+	var fetch []string
+	var fetchBytes int64
+	for _, ref := range unknown {
+		cached, err := s.localDB.HasCachedChunk(ref.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if !cached {
+			fetch = append(fetch, ref.Hash)
+			fetchBytes += int64(ref.Size)
+		}
+	}
+	if len(fetch) > 0 {
+		if err := s.fetchChunks(ctx, recipe.FileName, fetch, fetchBytes); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, ref := range recipe.Chunks {
+		if hash, ok := known[ref.Hash]; ok {
+			hashes[i] = hash
+			continue
+		}
+		data, ok, err := s.localDB.CachedChunk(ref.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("server did not return chunk %s", ref.Hash)
+		}
+		sum := sha256.Sum256(data)
+		known[ref.Hash] = hex.EncodeToString(sum[:])
+		hashes[i] = known[ref.Hash]
+	}
+	return hashes, nil
+}
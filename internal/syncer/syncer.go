@@ -0,0 +1,240 @@
+package syncer
+
+import (
+	"delta-sync/delta-sync-pb/pkg/pb"
+	"delta-sync/internal/auth"
+	"delta-sync/internal/chunker"
+	"delta-sync/internal/db"
+	"delta-sync/internal/encrypt"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+)
+
+// DefaultDBPath is where the local metadata database lives unless Config.DBPath says otherwise
+const DefaultDBPath = "client_metadata.db"
+
+// Config is everything a Syncer needs to know about the server and this machine
+type Config struct {
+	Addr string // server address, e.g. "sync.example.com:443"
+	// Token is the API key or JWT presented to the server; empty when the server runs open
+	Token string
+	// Credentials secure the connection; nil means TLS verified against the system roots
+	Credentials credentials.TransportCredentials
+	// Key is a 32-byte end-to-end encryption key; nil leaves chunks unencrypted
+	Key []byte
+
+	DBPath string // defaults to DefaultDBPath
+	// CacheLimit caps the local chunk cache in bytes: zero means db.DefaultCacheLimit, negative unlimited
+	CacheLimit int64
+	// Root names files on the server by their slash-separated path relative to it; when empty a
+	// file is named by its path exactly as passed to Sync
+	Root string
+	// Chunking sets the chunk size bounds; the zero value means chunker.DefaultParams. Changing
+	// them makes every file chunk differently, so the first push afterwards uploads it whole.
+	Chunking chunker.Params
+
+	// Logf receives progress messages; nil prints them to stdout
+	Logf func(format string, args ...any)
+	// Progress, if set, is called after every chunk sent or received
+	Progress func(Progress)
+}
+
+// Progress reports how far a single transfer has got
+type Progress struct {
+	File        string // server name of the file
+	Download    bool
+	ChunksDone  int
+	ChunksTotal int // chunks this transfer has to move, not the chunks in the file
+	BytesDone   int64
+	BytesTotal  int64
+}
+
+// Syncer pushes and restores files. It keeps one gRPC connection and one local database open
+// for its whole life, so consecutive syncs pay for neither a handshake nor a schema check.
+// It is safe for concurrent use, though callers should not sync the same path concurrently.
+type Syncer struct {
+	cfg     Config
+	conn    *grpc.ClientConn
+	client  pb.DeltaSyncClient
+	localDB *db.LocalDB
+	// sealer encrypts chunks before they leave the machine; nil when no key is configured.
+	// The local chunk cache always holds plaintext, keyed by the hash the server knows.
+	sealer *encrypt.Sealer
+}
+
+// New opens the local database and sets up the connection. The connection is established
+// lazily and re-established automatically with backoff whenever it drops; keepalive pings
+// notice a dead peer (e.g. behind a NAT that forgot us) between syncs.
+func New(cfg Config) (*Syncer, error) {
+	if cfg.Addr == "" {
+		return nil, fmt.Errorf("no server address configured")
+	}
+	if cfg.DBPath == "" {
+		cfg.DBPath = DefaultDBPath
+	}
+	if cfg.Logf == nil {
+		cfg.Logf = func(format string, args ...any) { fmt.Printf(format, args...) }
+	}
+	if cfg.Root != "" {
+		root, err := filepath.Abs(cfg.Root)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Root = root
+	}
+	if cfg.Chunking == (chunker.Params{}) {
+		cfg.Chunking = chunker.DefaultParams
+	}
+	if err := cfg.Chunking.Validate(); err != nil {
+		return nil, err
+	}
+
+	s := &Syncer{cfg: cfg}
+	if cfg.Key != nil {
+		sealer, err := encrypt.NewSealer(cfg.Key)
+		if err != nil {
+			return nil, err
+		}
+		s.sealer = sealer
+	}
+
+	creds := cfg.Credentials
+	if creds == nil {
+		creds = credentials.NewClientTLSFromCert(nil, "")
+	}
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                30 * time.Second,
+			Timeout:             10 * time.Second,
+			PermitWithoutStream: true,
+		}),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.Config{BaseDelay: time.Second, Multiplier: 1.6, Jitter: 0.2, MaxDelay: 30 * time.Second},
+			MinConnectTimeout: 10 * time.Second,
+		}),
+	}
+	if cfg.Token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(auth.TokenCredentials{
+			Token:         cfg.Token,
+			AllowInsecure: creds.Info().SecurityProtocol == "insecure",
+		}))
+	}
+	conn, err := grpc.NewClient(cfg.Addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	localDB, err := db.InitSQLite(cfg.DBPath)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	s.conn = conn
+	s.client = pb.NewDeltaSyncClient(conn)
+	s.localDB = localDB
+	switch {
+	case cfg.CacheLimit > 0:
+		s.localDB.CacheLimit = cfg.CacheLimit
+	case cfg.CacheLimit < 0:
+		s.localDB.CacheLimit = 0
+	}
+	return s, nil
+}
+
+// Close releases the connection and the local database
+func (s *Syncer) Close() error {
+	err := s.conn.Close()
+	if dbErr := s.localDB.Conn.Close(); err == nil {
+		err = dbErr
+	}
+	return err
+}
+
+// At returns a Syncer that shares this one's connection and database but names files relative
+// to root. Closing either closes both.
+func (s *Syncer) At(root string) *Syncer {
+	at := *s
+	at.cfg.Root = root
+	return &at
+}
+
+// Client exposes the underlying RPC client for calls the Syncer does not wrap
+func (s *Syncer) Client() pb.DeltaSyncClient {
+	return s.client
+}
+
+// LocalDB exposes the local index and chunk cache
+func (s *Syncer) LocalDB() *db.LocalDB {
+	return s.localDB
+}
+
+// Encrypted reports whether chunks are sealed before upload
+func (s *Syncer) Encrypted() bool {
+	return s.sealer != nil
+}
+
+// FileID is the name the server knows a local path by; paths outside Root keep their own name
+func (s *Syncer) FileID(path string) string {
+	if s.cfg.Root == "" {
+		return path
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	rel, err := filepath.Rel(s.cfg.Root, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// LocalPath maps a server name back below Root, rejecting names that would escape it
+func (s *Syncer) LocalPath(fileID string) (string, bool) {
+	rel := filepath.FromSlash(fileID)
+	if !filepath.IsLocal(rel) {
+		return "", false
+	}
+	return filepath.Join(s.cfg.Root, rel), true
+}
+
+// Ignored filters out hidden files/directories, editor backup and temp files, and the client's
+// own metadata database, none of which are ever synced
+func (s *Syncer) Ignored(path string) bool {
+	base := filepath.Base(path)
+	if strings.HasPrefix(base, ".") {
+		return true
+	}
+	// vim backups (file~) and JetBrains safe-write files
+	if strings.HasSuffix(base, "~") || strings.HasSuffix(base, "___jb_tmp___") || strings.HasSuffix(base, "___jb_old___") {
+		return true
+	}
+	return strings.HasPrefix(base, filepath.Base(s.cfg.DBPath))
+}
+
+// report passes p to the progress callback, if any
+func (s *Syncer) report(p Progress) {
+	if s.cfg.Progress != nil {
+		s.cfg.Progress(p)
+	}
+}
+
+// trimCache keeps the chunk cache within its limit once an operation no longer needs its chunks
+func (s *Syncer) trimCache() {
+	evicted, err := s.localDB.TrimCache()
+	if err != nil {
+		s.cfg.Logf("Cache eviction failed: %v\n", err)
+		return
+	}
+	if evicted > 0 {
+		if _, size, err := s.localDB.CacheUsage(); err == nil {
+			s.cfg.Logf("🧹 Evicted %d cached chunks (cache now %d bytes)\n", evicted, size)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package syncer
+
+import (
+	"context"
+	"crypto/sha256"
+	"delta-sync/delta-sync-pb/pkg/pb"
+	"encoding/hex"
+	"math/rand"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeServer serves files held in memory over the read side of the DeltaSync API
+type fakeServer struct {
+	pb.UnimplementedDeltaSyncServer
+
+	mu      sync.Mutex
+	files   map[string][]string // file name -> chunk hashes in order
+	updated map[string]time.Time
+	chunks  map[string][]byte
+}
+
+// put stores data under name, split into chunks of chunkSize bytes
+func (f *fakeServer) put(name string, data []byte, chunkSize int, updated time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var hashes []string
+	for off := 0; off < len(data); off += chunkSize {
+		chunk := data[off:min(off+chunkSize, len(data))]
+		sum := sha256.Sum256(chunk)
+		hash := hex.EncodeToString(sum[:])
+		f.chunks[hash] = chunk
+		hashes = append(hashes, hash)
+	}
+	f.files[name] = hashes
+	f.updated[name] = updated
+}
+
+func (f *fakeServer) GetRecipe(ctx context.Context, in *pb.FileRequest) (*pb.Recipe, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	hashes, ok := f.files[in.FileName]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "file %q not found", in.FileName)
+	}
+	recipe := &pb.Recipe{FileName: in.FileName, UpdatedAt: timestamppb.New(f.updated[in.FileName])}
+	for _, hash := range hashes {
+		recipe.Chunks = append(recipe.Chunks, &pb.ChunkRef{Hash: hash, Size: int32(len(f.chunks[hash]))})
+	}
+	return recipe, nil
+}
+
+func (f *fakeServer) ListFiles(ctx context.Context, in *pb.ListFilesRequest) (*pb.FileList, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	list := &pb.FileList{}
+	for name, hashes := range f.files {
+		list.Files = append(list.Files, &pb.FileInfo{FileName: name, ChunkHashes: hashes, UpdatedAt: timestamppb.New(f.updated[name])})
+	}
+	return list, nil
+}
+
+func (f *fakeServer) FetchChunks(in *pb.ChunkRequest, stream pb.DeltaSync_FetchChunksServer) error {
+	for _, hash := range in.Hashes {
+		f.mu.Lock()
+		data := f.chunks[hash]
+		f.mu.Unlock()
+		err := stream.Send(&pb.ChunkPayload{Hash: hash, Data: data, Size: int32(len(data)), Codec: pb.Codec_CODEC_STORED})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newTestSyncer starts a fake server and returns it with a Syncer rooted at a temporary directory
+func newTestSyncer(t *testing.T, cacheLimit int64) (*Syncer, *fakeServer) {
+	t.Helper()
+	fake := &fakeServer{files: map[string][]string{}, updated: map[string]time.Time{}, chunks: map[string][]byte{}}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	pb.RegisterDeltaSyncServer(server, fake)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	s, err := New(Config{
+		Addr:        lis.Addr().String(),
+		Credentials: insecure.NewCredentials(),
+		DBPath:      filepath.Join(t.TempDir(), "client.db"),
+		CacheLimit:  cacheLimit,
+		Root:        t.TempDir(),
+		Logf:        func(format string, args ...any) { t.Logf(format, args...) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s, fake
+}
+
+func randomBytes(seed int64, n int) []byte {
+	data := make([]byte, n)
+	rand.New(rand.NewSource(seed)).Read(data)
+	return data
+}
@@ -0,0 +1,290 @@
+package syncer
+
+import (
+	"context"
+	"delta-sync/internal/scheduler"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchOptions tune Watch
+type WatchOptions struct {
+	// Scheduler controls how watcher events are debounced and how many syncs run at once
+	Scheduler scheduler.Options
+	// PullInterval, when watching a directory, polls the server this often and pulls newer files
+	// into the tree; zero disables pulling
+	PullInterval time.Duration
+}
+
+// Watch keeps path in sync until ctx is done, then returns ctx.Err(). A file is pushed under
+// its FileID; a directory is synced recursively with files named relative to it.
+func (s *Syncer) Watch(ctx context.Context, path string, opts WatchOptions) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	// Index entries are keyed by absolute path so Status and later pushes find them
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return s.watchFile(ctx, watcher, abs, opts)
+	}
+	return s.At(abs).watchDirectory(ctx, watcher, abs, opts)
+}
+
+// newScheduler debounces syncs with the given options and reports a backlog when a sync starts
+func (s *Syncer) newScheduler(opts scheduler.Options, sync func(path string)) *scheduler.Scheduler {
+	var sched *scheduler.Scheduler
+	sched = scheduler.New(func(path string) {
+		waiting := 0
+		for _, job := range sched.Jobs() {
+			if job.State == scheduler.Queued {
+				waiting++
+			}
+		}
+		if waiting > 0 {
+			s.cfg.Logf("⏳ Syncing %s (%d more queued)\n", path, waiting)
+		}
+		sync(path)
+	}, opts)
+	return sched
+}
+
+// watchFile keeps a single file in sync. The watch sits on the parent directory rather than the
+// file: editors that save by renaming a temp file over the original replace the inode, which
+// would silently end a watch on the file itself.
+func (s *Syncer) watchFile(ctx context.Context, watcher *fsnotify.Watcher, filePath string, opts WatchOptions) error {
+	target := filepath.Clean(filePath)
+	dir := filepath.Dir(target)
+
+	// Bursts of events from one save collapse into a single sync
+	sched := s.newScheduler(opts.Scheduler, func(path string) {
+		if _, err := os.Stat(path); err != nil {
+			s.cfg.Logf("⚠️  %s is gone; waiting for it to reappear\n", path)
+			return
+		}
+		if _, err := s.Push(ctx, filePath, s.FileID(filePath)); err != nil && ctx.Err() == nil {
+			s.cfg.Logf("Sync of %s failed: %v\n", filePath, err)
+		}
+	})
+	defer sched.Close()
+
+	if err := watcher.Add(dir); err != nil {
+		return err
+	}
+
+	s.cfg.Logf("👁️  Delta-Sync Watcher active on: %s\n", filePath)
+
+	sched.Touch(target)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			switch name := filepath.Clean(event.Name); {
+			case name == target && event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Rename|fsnotify.Remove) != 0:
+				// Create, Rename and Remove are how atomic saves look; whatever is there once
+				// things settle is the new version
+				s.cfg.Logf("📝 Changes detected in: %s\n", filePath)
+				sched.Touch(target)
+			case name == dir && event.Op&(fsnotify.Rename|fsnotify.Remove) != 0:
+				// The directory itself went away, taking the watch with it
+				s.rearmWatch(ctx, watcher, dir, func() { sched.Touch(target) })
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			s.cfg.Logf("watcher error: %v\n", err)
+		}
+	}
+}
+
+// rearmWatch re-adds the watch on dir once it exists again, then calls onArmed
+func (s *Syncer) rearmWatch(ctx context.Context, watcher *fsnotify.Watcher, dir string, onArmed func()) {
+	s.cfg.Logf("⚠️  %s disappeared; watching for it to return\n", dir)
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := watcher.Add(dir); err == nil {
+				s.cfg.Logf("👁️  Watching %s again\n", dir)
+				onArmed()
+				return
+			}
+		}
+	}()
+}
+
+// watchDirectory syncs a whole project tree and keeps every subdirectory under watch.
+// With a non-zero PullInterval it also pulls newer remote files into the tree.
+func (s *Syncer) watchDirectory(ctx context.Context, watcher *fsnotify.Watcher, root string, opts WatchOptions) error {
+	s.cfg.Logf("👁️  Delta-Sync Watcher active on directory: %s\n", root)
+
+	// Syncs run on the scheduler's workers; the event loop only records what changed. A path
+	// that is missing once its events settle was really deleted rather than replaced by an
+	// atomic save, so only then does the deletion reach the server.
+	sched := s.newScheduler(opts.Scheduler, func(path string) {
+		info, err := os.Stat(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			err = s.Delete(ctx, path)
+		case err != nil:
+		case !info.IsDir():
+			_, err = s.Push(ctx, path, s.FileID(path))
+		}
+		if err != nil && ctx.Err() == nil {
+			s.cfg.Logf("⚠️  %s: %v\n", path, err)
+		}
+	})
+	defer sched.Close()
+
+	// 1. Initial pass: watch every directory and push every file once
+	s.addTree(watcher, sched, root, root)
+
+	// 2. Pulls run exclusively of syncs so a download never races a local sync. They run off the
+	// event loop, which must keep draining events while a pull waits for syncs and downloads, and
+	// at most one at a time: a tick that finds a pull still running is skipped.
+	var pulls sync.WaitGroup
+	var pulling atomic.Bool
+	defer pulls.Wait()
+	pull := func() {
+		if !pulling.CompareAndSwap(false, true) {
+			return
+		}
+		pulls.Add(1)
+		go func() {
+			defer pulls.Done()
+			defer pulling.Store(false)
+			sched.Exclusive(func() {
+				if ctx.Err() != nil {
+					return
+				}
+				if err := s.PullChanges(ctx); err != nil && ctx.Err() == nil {
+					s.cfg.Logf("%v\n", err)
+				}
+			})
+		}()
+	}
+	var pullTick <-chan time.Time
+	if opts.PullInterval > 0 {
+		s.cfg.Logf("🔄 Bidirectional mode: pulling remote changes every %s\n", opts.PullInterval)
+		ticker := time.NewTicker(opts.PullInterval)
+		defer ticker.Stop()
+		pullTick = ticker.C
+		pull()
+	}
+
+	// 3. Monitoring loop
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-pullTick:
+			pull()
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			s.handleTreeEvent(watcher, sched, event, root)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			s.cfg.Logf("watcher error: %v\n", err)
+		}
+	}
+}
+
+// handleTreeEvent reacts to a single fsnotify event inside the watched tree
+func (s *Syncer) handleTreeEvent(watcher *fsnotify.Watcher, sched *scheduler.Scheduler, event fsnotify.Event, root string) {
+	if s.Ignored(event.Name) {
+		return
+	}
+
+	switch {
+	case event.Op&fsnotify.Create == fsnotify.Create:
+		info, err := os.Stat(event.Name)
+		if err != nil {
+			return
+		}
+		if info.IsDir() {
+			// New directories (including ones moved into the tree) need their own watches
+			s.cfg.Logf("📁 New directory detected: %s\n", event.Name)
+			s.addTree(watcher, sched, root, event.Name)
+			return
+		}
+		s.cfg.Logf("🆕 New file detected: %s\n", event.Name)
+		sched.Touch(event.Name)
+
+	case event.Op&fsnotify.Write == fsnotify.Write:
+		if info, err := os.Stat(event.Name); err != nil || info.IsDir() {
+			return
+		}
+		s.cfg.Logf("📝 Changes detected in: %s\n", event.Name)
+		sched.Touch(event.Name)
+
+	case event.Op&(fsnotify.Remove|fsnotify.Rename) != 0:
+		// A rename shows up as Rename on the old name followed by Create on the new one. The old
+		// name is scheduled like any change: if something is renamed over it before it settles
+		// (an atomic save) it syncs as a new version, otherwise it is deleted.
+		if _, err := os.Stat(event.Name); err == nil {
+			return
+		}
+		sched.Touch(event.Name)
+	}
+}
+
+// addTree walks dir recursively, adding a watch to every directory and scheduling every file for sync
+func (s *Syncer) addTree(watcher *fsnotify.Watcher, sched *scheduler.Scheduler, root string, dir string) {
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			s.cfg.Logf("⚠️  Skipping %s: %v\n", path, err)
+			return nil
+		}
+		if path != root && s.Ignored(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if err := watcher.Add(path); err != nil {
+				s.cfg.Logf("⚠️  Could not watch %s: %v\n", path, err)
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			sched.Touch(path)
+		}
+		return nil
+	})
+	if err != nil {
+		s.cfg.Logf("Walk failed: %v\n", err)
+	}
+}
@@ -0,0 +1,221 @@
+// Package deltasync pushes files to and pulls files from a delta-sync server. Files are split
+// into content-defined chunks and only chunks the other side lacks are transferred; a local
+// database remembers what was synced so unchanged files cost nothing.
+//
+// The module is named delta-sync, which is not a fetchable import path, so `go get` cannot
+// download it. Check the repository out next to your own module and point at it instead:
+//
+//	require delta-sync v0.0.0
+//	replace delta-sync => ../delta-sync
+package deltasync
+
+import (
+	"context"
+	"delta-sync/delta-sync-pb/pkg/pb"
+	"delta-sync/internal/encrypt"
+	"delta-sync/internal/syncer"
+	"time"
+)
+
+// Client talks to one server. It is safe for concurrent use, though the same local file should
+// not be pushed or pulled by two calls at once.
+type Client struct {
+	s     *syncer.Syncer
+	watch syncer.WatchOptions
+}
+
+// Progress reports how far a single push or pull has got
+type Progress struct {
+	File        string // server name of the file
+	Download    bool
+	ChunksDone  int
+	ChunksTotal int // chunks this transfer has to move, not the chunks in the file
+	BytesDone   int64
+	BytesTotal  int64
+}
+
+// PushResult describes what a push did
+type PushResult struct {
+	Name          string
+	Version       int  // version published; 0 when nothing changed
+	Unchanged     bool // the server already had exactly this content
+	Chunks        int  // chunks in the file
+	Uploaded      int  // chunks actually sent
+	UploadedBytes int64
+}
+
+// FileInfo describes a file on the server
+type FileInfo struct {
+	Name      string
+	UpdatedAt time.Time
+	Hashes    []string // chunk hashes of the current version, as the server knows them
+}
+
+// FileStat is what Stat reports about a file's current version
+type FileStat struct {
+	FileInfo
+	Version int
+	Size    int64 // plaintext size, provided the client holds the key the file was pushed with
+}
+
+// Version is one entry of a file's history
+type Version struct {
+	Number    int
+	CreatedAt time.Time
+	Size      int64
+	Chunks    int
+}
+
+// FileState is how a local file compares with the server
+type FileState string
+
+// File states reported by Status
+const (
+	InSync    FileState = "synced"    // unchanged on both sides since the last sync
+	Modified  FileState = "modified"  // changed locally since the last sync
+	Outdated  FileState = "outdated"  // changed on the server since the last sync
+	Conflict  FileState = "conflict"  // changed on both sides
+	Added     FileState = "added"     // only exists locally
+	Deleted   FileState = "deleted"   // synced before but gone locally
+	Remote    FileState = "remote"    // only exists on the server
+	Untracked FileState = "untracked" // exists on both sides but was never synced by this client
+)
+
+// FileStatus is one entry of Status
+type FileStatus struct {
+	Name  string // server name
+	Path  string // local path
+	State FileState
+}
+
+// New connects to the server named by WithAddress. The connection is made lazily, so New only
+// fails on bad options or a local database that cannot be opened or migrated; both come back as
+// errors rather than ending the process.
+func New(opts ...Option) (*Client, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.cfg.Logf == nil {
+		o.cfg.Logf = func(string, ...any) {}
+	}
+	s, err := syncer.New(o.cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{s: s, watch: o.watch}, nil
+}
+
+// Close releases the connection and the local database
+func (c *Client) Close() error {
+	return c.s.Close()
+}
+
+// PushFile uploads the local file at path as name, publishing a new version unless the server
+// already holds exactly this content. An empty name uses path as given.
+func (c *Client) PushFile(ctx context.Context, path, name string) (*PushResult, error) {
+	if name == "" {
+		name = c.s.FileID(path)
+	}
+	r, err := c.s.Push(ctx, path, name)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &PushResult{
+		Name:          r.FileID,
+		Version:       r.Version,
+		Unchanged:     r.Unchanged,
+		Chunks:        r.Chunks,
+		Uploaded:      r.Uploaded,
+		UploadedBytes: r.UploadedBytes,
+	}, nil
+}
+
+// PullFile writes the current version of name to dest, downloading only chunks that are not in
+// the local cache. dest is replaced atomically.
+func (c *Client) PullFile(ctx context.Context, name, dest string) error {
+	return c.PullVersion(ctx, name, 0, dest)
+}
+
+// PullVersion is PullFile for a specific version from the file's history; 0 means the current one
+func (c *Client) PullVersion(ctx context.Context, name string, version int, dest string) error {
+	return mapError(c.s.Pull(ctx, name, version, dest))
+}
+
+// ListFiles returns every file on the server that the caller owns
+func (c *Client) ListFiles(ctx context.Context) ([]FileInfo, error) {
+	list, err := c.s.Client().ListFiles(ctx, &pb.ListFilesRequest{})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	files := make([]FileInfo, 0, len(list.Files))
+	for _, f := range list.Files {
+		files = append(files, FileInfo{Name: f.FileName, UpdatedAt: f.UpdatedAt.AsTime(), Hashes: f.ChunkHashes})
+	}
+	return files, nil
+}
+
+// Stat describes the current version of name
+func (c *Client) Stat(ctx context.Context, name string) (*FileStat, error) {
+	recipe, err := c.s.Client().GetRecipe(ctx, &pb.FileRequest{FileName: name})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	st := &FileStat{
+		FileInfo: FileInfo{Name: recipe.FileName, UpdatedAt: recipe.UpdatedAt.AsTime()},
+		Version:  int(recipe.Version),
+	}
+	for _, ref := range recipe.Chunks {
+		st.Hashes = append(st.Hashes, ref.Hash)
+		// The server only knows the size of what it stores, which for sealed chunks includes the seal
+		st.Size += int64(ref.Size)
+		if c.s.Encrypted() {
+			st.Size -= encrypt.Overhead
+		}
+	}
+	return st, nil
+}
+
+// History lists every stored version of name, newest first
+func (c *Client) History(ctx context.Context, name string) ([]Version, error) {
+	list, err := c.s.Client().ListVersions(ctx, &pb.FileRequest{FileName: name})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	versions := make([]Version, 0, len(list.Versions))
+	for _, v := range list.Versions {
+		versions = append(versions, Version{
+			Number:    int(v.Version),
+			CreatedAt: v.CreatedAt.AsTime(),
+			Size:      v.Size,
+			Chunks:    int(v.ChunkCount),
+		})
+	}
+	return versions, nil
+}
+
+// Status compares every file below the WithRoot directory with the server. It trusts file
+// metadata and the local database rather than reading files; VerifyFile checks content.
+func (c *Client) Status(ctx context.Context) ([]FileStatus, error) {
+	statuses, err := c.s.Status(ctx)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	out := make([]FileStatus, 0, len(statuses))
+	for _, st := range statuses {
+		out = append(out, FileStatus{Name: st.Name, Path: st.Path, State: FileState(st.State)})
+	}
+	return out, nil
+}
+
+// VerifyFile checks that the local file at path holds exactly the current version of name,
+// returning a *MismatchError if it does not
+func (c *Client) VerifyFile(ctx context.Context, path, name string) error {
+	return mapError(c.s.Verify(ctx, path, name))
+}
+
+// Watch keeps path, a file or a directory tree, in sync until ctx is done, then returns
+// ctx.Err(). Files in a tree are named by their slash-separated path relative to it.
+func (c *Client) Watch(ctx context.Context, path string) error {
+	return mapError(c.s.Watch(ctx, path, c.watch))
+}
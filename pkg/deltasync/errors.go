@@ -0,0 +1,90 @@
+package deltasync
+
+import (
+	"delta-sync/internal/syncer"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Errors returned by Client methods can be matched with errors.Is
+var (
+	ErrNotFound         = errors.New("deltasync: file not found")
+	ErrUnauthenticated  = errors.New("deltasync: not authenticated")
+	ErrPermissionDenied = errors.New("deltasync: permission denied")
+	ErrUnavailable      = errors.New("deltasync: server unavailable")
+)
+
+// RejectedError reports a chunk the server refused because its data did not match its hash,
+// e.g. because the file changed while it was being pushed
+type RejectedError struct {
+	Hash   string
+	Stored int // chunks of the push stored before the rejected one
+	Reason string
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("deltasync: chunk %s rejected after %d stored: %s", e.Hash, e.Stored, e.Reason)
+}
+
+// CommitError reports a version the server would not publish
+type CommitError struct {
+	Reason string
+}
+
+func (e *CommitError) Error() string {
+	return "deltasync: commit refused: " + e.Reason
+}
+
+// MismatchError reports where a local file first differs from the server's copy
+type MismatchError struct {
+	Name   string
+	Offset int64
+	Reason string
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("deltasync: %s differs from the server at byte %d: %s", e.Name, e.Offset, e.Reason)
+}
+
+// mapError turns what the syncer and gRPC report into the errors above, keeping the original
+// message. Context errors and local I/O errors pass through unchanged.
+func mapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var rejected *syncer.RejectedError
+	if errors.As(err, &rejected) {
+		return &RejectedError{Hash: rejected.Hash, Stored: rejected.Stored, Reason: rejected.Reason}
+	}
+	var commit *syncer.CommitError
+	if errors.As(err, &commit) {
+		return &CommitError{Reason: commit.Reason}
+	}
+	var mismatch *syncer.MismatchError
+	if errors.As(err, &mismatch) {
+		return &MismatchError{Name: mismatch.Name, Offset: mismatch.Offset, Reason: mismatch.Reason}
+	}
+
+	// The syncer wraps RPC errors with %w, so look for a status anywhere in the chain
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if !errors.As(err, &grpcErr) {
+		return err
+	}
+	var sentinel error
+	switch grpcErr.GRPCStatus().Code() {
+	case codes.NotFound:
+		sentinel = ErrNotFound
+	case codes.Unauthenticated:
+		sentinel = ErrUnauthenticated
+	case codes.PermissionDenied:
+		sentinel = ErrPermissionDenied
+	case codes.Unavailable:
+		sentinel = ErrUnavailable
+	default:
+		return err
+	}
+	return fmt.Errorf("%w: %s", sentinel, grpcErr.GRPCStatus().Message())
+}
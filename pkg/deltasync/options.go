@@ -0,0 +1,99 @@
+package deltasync
+
+import (
+	"delta-sync/internal/chunker"
+	"delta-sync/internal/syncer"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Option configures a Client
+type Option func(*options)
+
+type options struct {
+	cfg   syncer.Config
+	watch syncer.WatchOptions
+}
+
+// WithAddress sets the server address, e.g. "sync.example.com:443". It is required.
+func WithAddress(addr string) Option {
+	return func(o *options) { o.cfg.Addr = addr }
+}
+
+// WithToken sets the API key or JWT presented to the server
+func WithToken(token string) Option {
+	return func(o *options) { o.cfg.Token = token }
+}
+
+// WithRoot names files by their slash-separated path relative to dir rather than by the path as
+// given, and is the tree Status compares with the server
+func WithRoot(dir string) Option {
+	return func(o *options) { o.cfg.Root = dir }
+}
+
+// WithTransportCredentials secures the connection with creds instead of TLS verified against
+// the system roots
+func WithTransportCredentials(creds credentials.TransportCredentials) Option {
+	return func(o *options) { o.cfg.Credentials = creds }
+}
+
+// WithInsecure connects without TLS, e.g. to a server on localhost
+func WithInsecure() Option {
+	return WithTransportCredentials(insecure.NewCredentials())
+}
+
+// WithEncryptionKey seals every chunk with a 32-byte key before it leaves the machine. Files
+// pushed with a key can only be pulled with the same key.
+func WithEncryptionKey(key []byte) Option {
+	return func(o *options) { o.cfg.Key = key }
+}
+
+// WithChunkSizes sets the minimum, average and maximum chunk size in bytes. Clients that share
+// files should agree on them, or identical content will not deduplicate.
+func WithChunkSizes(minSize, avgSize, maxSize int) Option {
+	return func(o *options) {
+		o.cfg.Chunking = chunker.Params{MinSize: minSize, AvgSize: avgSize, MaxSize: maxSize}
+	}
+}
+
+// WithDatabase sets where the local index and chunk cache live
+func WithDatabase(path string) Option {
+	return func(o *options) { o.cfg.DBPath = path }
+}
+
+// WithCacheLimit caps the local chunk cache in bytes; negative means unlimited
+func WithCacheLimit(bytes int64) Option {
+	return func(o *options) { o.cfg.CacheLimit = bytes }
+}
+
+// WithProgress calls fn after every chunk a push or pull transfers. It may be called from
+// several goroutines at once while watching.
+func WithProgress(fn func(Progress)) Option {
+	return func(o *options) {
+		o.cfg.Progress = func(p syncer.Progress) { fn(Progress(p)) }
+	}
+}
+
+// WithLogger receives the human-readable messages the command-line client prints; by default
+// they are discarded
+func WithLogger(logf func(format string, args ...any)) Option {
+	return func(o *options) { o.cfg.Logf = logf }
+}
+
+// WithDebounce sets how long a watched file must stay unchanged before it is pushed
+func WithDebounce(d time.Duration) Option {
+	return func(o *options) { o.watch.Scheduler.Debounce = d }
+}
+
+// WithWorkers sets how many files Watch may push at the same time
+func WithWorkers(n int) Option {
+	return func(o *options) { o.watch.Scheduler.Workers = n }
+}
+
+// WithPullInterval makes Watch on a directory poll the server this often and pull newer files
+// into it
+func WithPullInterval(d time.Duration) Option {
+	return func(o *options) { o.watch.PullInterval = d }
+}